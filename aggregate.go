@@ -0,0 +1,173 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Aggregate denotes the statistics of a series of runs of a simulator
+type Aggregate struct {
+	Name   string
+	Budget time.Duration
+	Runs   int
+	// Warmup is the number of runs done before the series, left out of the statistics
+	Warmup int
+	Seed   int64
+	// Timeouts is the number of runs that reached the deadline
+	Timeouts int
+	// Completion holds the time consumed by every run, sorted
+	Completion []time.Duration
+	// Processes holds the statistics of every process, in the order they were first recorded
+	Processes []ProcessAggregate
+}
+
+// ProcessAggregate denotes the statistics of a single process over a series of runs
+type ProcessAggregate struct {
+	Name       string
+	Depth      int
+	Executed   int
+	Failed     int
+	Skipped    int
+	Unexecuted int
+	// Consumed holds the time consumed by every executed run of the process, sorted
+	Consumed []time.Duration
+}
+
+// RunN runs the simulator n times and returns the statistics of the series. The
+// processes consuming randomness are seeded from seed first, so the series is
+// reproducible. With WithWarmupRuns the warm-up runs are done first and left out of
+// the statistics, the processes then keep their warm state but their counters, such as
+// the failures of a circuit breaker, start fresh. Nothing is printed by the runs, use
+// Aggregate.WriteTable to print a summary.
+func (s *Simulator) RunN(n int, seed int64) (*Aggregate, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("t0simulator: simulator %q: %d runs lower than 1", s.name, n)
+	}
+	s.running.Lock()
+	defer s.running.Unlock()
+	// the series runs the registered processes quietly, seeded from seed
+	series := s.config
+	series.seeding = &seeding{seed: seed}
+	series.verbosity = Quiet
+	defer s.seeding.restart()
+
+	agg := &Aggregate{Name: s.name, Runs: n, Warmup: s.warmup, Seed: seed}
+	for i := 0; i < s.warmup; i++ {
+		if _, err := s.run(context.Background(), &series); err != nil && !errors.Is(err, ErrBudgetExceeded) && !errors.Is(err, ErrSLOViolated) {
+			return nil, err
+		}
+	}
+	if s.warmup > 0 {
+		for _, p := range s.process {
+			resetCounters(p)
+		}
+	}
+	index := make(map[string]int)
+	for i := 0; i < n; i++ {
+		res, err := s.run(context.Background(), &series)
+		if err != nil && !errors.Is(err, ErrBudgetExceeded) && !errors.Is(err, ErrSLOViolated) {
+			return nil, err
+		}
+		agg.Budget = res.Budget
+		if res.TimedOut {
+			agg.Timeouts++
+		}
+		agg.Completion = append(agg.Completion, res.Summary.Consumed)
+		for _, rec := range res.Records {
+			j, ok := index[rec.Name]
+			if !ok {
+				j = len(agg.Processes)
+				index[rec.Name] = j
+				agg.Processes = append(agg.Processes, ProcessAggregate{Name: rec.Name, Depth: rec.Depth})
+			}
+			p := &agg.Processes[j]
+			switch rec.Status() {
+			case StatusUnexecuted, StatusInProgress:
+				p.Unexecuted++
+				continue
+			case StatusSkipped:
+				p.Skipped++
+				continue
+			case StatusFailed:
+				p.Failed++
+			}
+			p.Executed++
+			p.Consumed = append(p.Consumed, rec.Consumed)
+		}
+	}
+	sortDurations(agg.Completion)
+	for i := range agg.Processes {
+		sortDurations(agg.Processes[i].Consumed)
+	}
+	return agg, nil
+}
+
+// TimeoutRate returns the fraction of runs that reached the deadline
+func (a *Aggregate) TimeoutRate() float64 {
+	return float64(a.Timeouts) / float64(a.Runs)
+}
+
+// Percentile returns the p-th percentile of the time consumed by the runs, p in [0,100]
+func (a *Aggregate) Percentile(p float64) time.Duration {
+	return percentile(a.Completion, p)
+}
+
+// Mean returns the mean time consumed by the executed runs of the process
+func (p ProcessAggregate) Mean() time.Duration {
+	if len(p.Consumed) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range p.Consumed {
+		total += d
+	}
+	return total / time.Duration(len(p.Consumed))
+}
+
+// Percentile returns the p-th percentile of the time consumed by the executed runs of
+// the process, p in [0,100]
+func (p ProcessAggregate) Percentile(pct float64) time.Duration {
+	return percentile(p.Consumed, pct)
+}
+
+// WriteTable writes a summary of the series to w, in the layout of the report table
+func (a *Aggregate) WriteTable(w io.Writer) error {
+	tw := newTabWriter(w, defaultTabConfig)
+	fmt.Fprint(tw, "=====================\n")
+	if a.Warmup > 0 {
+		fmt.Fprintf(tw, "SIMULATOR:%s (%d runs after %d warm-up, seed %d)\n", a.Name, a.Runs, a.Warmup, a.Seed)
+	} else {
+		fmt.Fprintf(tw, "SIMULATOR:%s (%d runs, seed %d)\n", a.Name, a.Runs, a.Seed)
+	}
+	fmt.Fprint(tw, "Name\tMean(ms)\tp50(ms)\tp95(ms)\tp99(ms)\tExecuted\tFailed\tSkipped\tUnexecuted\t\n")
+	for _, p := range a.Processes {
+		fmt.Fprintf(tw, "%s%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n", strings.Repeat("  ", p.Depth), p.Name,
+			p.Mean().Milliseconds(), p.Percentile(50).Milliseconds(), p.Percentile(95).Milliseconds(), p.Percentile(99).Milliseconds(),
+			p.Executed, p.Failed, p.Skipped, p.Unexecuted)
+	}
+	fmt.Fprintf(tw, "Timed out %d of %d runs (%.1f%%)\n", a.Timeouts, a.Runs, a.TimeoutRate()*100)
+	fmt.Fprintf(tw, "Consumed p50 %d ms, p95 %d ms, p99 %d ms of %d ms\n",
+		a.Percentile(50).Milliseconds(), a.Percentile(95).Milliseconds(), a.Percentile(99).Milliseconds(), a.Budget.Milliseconds())
+	fmt.Fprint(tw, "=====================\n")
+	return tw.Flush()
+}
+
+func sortDurations(ds []time.Duration) {
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+}
+
+// percentile returns the p-th percentile of sorted using the nearest rank, zero when empty
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
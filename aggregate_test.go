@@ -0,0 +1,49 @@
+package t0simulator
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunN(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("series", 100, WithVirtualClock(), WithOutput(&out))
+	// b times out whenever a misses its cache
+	s.RegisterFunctions(NewFunction("a").WithCache(0.5, 10, 80), NewFunction("b").WithTimeout(30))
+	agg, err := s.RunN(50, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("series printed\n%s", out.String())
+	}
+	if agg.Runs != 50 || len(agg.Completion) != 50 || len(agg.Processes) != 2 {
+		t.Fatalf("%d runs, %d completions, %d processes", agg.Runs, len(agg.Completion), len(agg.Processes))
+	}
+	a, b := agg.Processes[0], agg.Processes[1]
+	if a.Name != "a" || a.Executed != 50 || len(a.Consumed) != 50 {
+		t.Errorf("a executed %d times, want every run", a.Executed)
+	}
+	if b.Name != "b" || b.Executed+b.Unexecuted != 50 || b.Unexecuted != agg.Timeouts || agg.Timeouts == 0 || agg.Timeouts == 50 {
+		t.Errorf("b executed %d times and unexecuted %d times over %d timeouts", b.Executed, b.Unexecuted, agg.Timeouts)
+	}
+	if agg.Percentile(0) != 40*time.Millisecond || agg.Percentile(100) != 100*time.Millisecond {
+		t.Errorf("consumed from %v to %v, want from 40ms to 100ms", agg.Percentile(0), agg.Percentile(100))
+	}
+	if !slices.IsSorted(agg.Completion) || !slices.IsSorted(a.Consumed) {
+		t.Error("durations not sorted")
+	}
+
+	again, err := s.RunN(50, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Timeouts != agg.Timeouts || !slices.Equal(again.Completion, agg.Completion) {
+		t.Errorf("series not reproduced from the seed: %d timeouts, want %d", again.Timeouts, agg.Timeouts)
+	}
+	if _, err := s.RunN(0, 42); err == nil {
+		t.Error("series of 0 runs accepted")
+	}
+}
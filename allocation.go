@@ -0,0 +1,289 @@
+package t0simulator
+
+import (
+	"context"
+	"time"
+)
+
+// AllocationRequest denotes the request of a dynamic context function for its timeout
+type AllocationRequest struct {
+	// Name is the name of the function
+	Name string
+	// Remaining is the budget left when the function starts
+	Remaining time.Duration
+	Weight    float64
+	Priority  Priority
+	// Threshold is the priority threshold of the run, see WithPriorityThreshold
+	Threshold time.Duration
+	// MinTimeout is the timeout the function needs at least, see WithMinTimeout, zero for none
+	MinTimeout time.Duration
+	// Pending holds the registered processes that run after the function in a
+	// sequential run, nil in a parallel one
+	Pending []Proccess
+	// Planned is the slice of the original budget proportional to the weight of the
+	// function among those of all the registered ones, once the declared timeouts of the
+	// other processes are taken out, see ProportionalAllocation
+	Planned time.Duration
+}
+
+// AllocationStrategy computes the timeouts of dynamic context functions. Allocate
+// returns the timeout of the function requesting it, the function is skipped when it
+// is not positive. The timeout is then raised to the MinTimeout of the request when
+// the remaining budget allows, and clamped to the bounds set with WithTimeoutBounds.
+type AllocationStrategy interface {
+	Allocate(req AllocationRequest) time.Duration
+}
+
+// allotter is implemented by the built-in strategies, which tell how the timeout was
+// allotted so the row of the function reports it
+type allotter interface {
+	allotment(req AllocationRequest) allotment
+}
+
+// WithAllocationStrategy sets how the timeouts of dynamic context functions are
+// computed, WeightedAllocation by default
+func WithAllocationStrategy(st AllocationStrategy) Option {
+	return func(s *Simulator) error {
+		s.allocation = st
+		return nil
+	}
+}
+
+// WeightedAllocation allots its weight of the remaining budget to a function, with the
+// escalations and demotions of its priority, see Priority. It is the default strategy.
+type WeightedAllocation struct{}
+
+// Allocate returns the weight of the remaining budget, see WeightedAllocation
+func (WeightedAllocation) Allocate(req AllocationRequest) time.Duration {
+	return WeightedAllocation{}.allotment(req).timeout()
+}
+
+func (WeightedAllocation) allotment(req AllocationRequest) allotment {
+	reserved := time.Duration(len(higher(req.Pending, req.Priority))) * req.Threshold
+	return allot(req.Remaining, req.Weight, req.Priority, req.Threshold, reserved, req.MinTimeout)
+}
+
+// EqualSplitAllocation splits the remaining budget evenly between a function and the
+// processes registered after it, whatever their weight
+type EqualSplitAllocation struct{}
+
+// Allocate returns an even split of the remaining budget, see EqualSplitAllocation
+func (EqualSplitAllocation) Allocate(req AllocationRequest) time.Duration {
+	return EqualSplitAllocation{}.allotment(req).timeout()
+}
+
+func (EqualSplitAllocation) allotment(req AllocationRequest) allotment {
+	return floorAllotment(allotment{allotted: req.Remaining / time.Duration(len(req.Pending)+1)}, req)
+}
+
+// PriorityFirstAllocation serves the functions of a higher priority registered later
+// first: a function is allotted its weight of what is left of the remaining budget once
+// their weights are taken out of it, and is skipped when nothing is left
+type PriorityFirstAllocation struct{}
+
+// Allocate returns the share of the function once the higher priorities are served,
+// see PriorityFirstAllocation
+func (PriorityFirstAllocation) Allocate(req AllocationRequest) time.Duration {
+	return PriorityFirstAllocation{}.allotment(req).timeout()
+}
+
+func (PriorityFirstAllocation) allotment(req AllocationRequest) allotment {
+	left := 1.0
+	for _, f := range higher(req.Pending, req.Priority) {
+		left -= f.weight
+	}
+	left = max(left, 0)
+	a := allotment{allotted: time.Duration(float64(req.Remaining) * req.Weight * left), demoted: left < 1}
+	if a.allotted <= 0 {
+		a.skipped = true
+		return a
+	}
+	return floorAllotment(a, req)
+}
+
+// ProportionalAllocation plans the timeouts upfront: when the run starts, the declared
+// timeouts of the other processes are taken out of the original budget, and every dynamic
+// context function is assigned a slice of the rest proportional to its weight among those
+// of all the registered ones, whatever the order they run in. The weights are normalized
+// when they sum to more than 1. A function is allotted its slice, or what is left when
+// less is.
+type ProportionalAllocation struct{}
+
+func (ProportionalAllocation) planner() {}
+
+// Allocate returns the slice planned for the function, see ProportionalAllocation
+func (ProportionalAllocation) Allocate(req AllocationRequest) time.Duration {
+	return ProportionalAllocation{}.allotment(req).timeout()
+}
+
+func (ProportionalAllocation) allotment(req AllocationRequest) allotment {
+	a := allotment{allotted: min(req.Planned, req.Remaining), planned: req.Planned}
+	if a.allotted <= 0 {
+		a.skipped = true
+		return a
+	}
+	return floorAllotment(a, req)
+}
+
+// allocate returns the allotment of st for req clamped to [min,max], a zero bound is
+// ignored. st is the default strategy when nil.
+func allocate(st AllocationStrategy, req AllocationRequest, min, max time.Duration) allotment {
+	var a allotment
+	switch s := st.(type) {
+	case nil:
+		a = WeightedAllocation{}.allotment(req)
+	case allotter:
+		a = s.allotment(req)
+	default:
+		a = allotment{allotted: st.Allocate(req)}
+		if a.allotted <= 0 {
+			a.skipped = true
+			break
+		}
+		a = floorAllotment(a, req)
+	}
+	if a.skipped || a.insufficient {
+		return a
+	}
+	if min > 0 && a.allotted < min {
+		a.allotted, a.clamped = min, true
+	}
+	if max > 0 && a.allotted > max {
+		a.allotted, a.clamped = max, true
+	}
+	return a
+}
+
+// floorAllotment raises a to the MinTimeout of req when the remaining budget allows, a
+// is insufficient otherwise
+func floorAllotment(a allotment, req AllocationRequest) allotment {
+	switch {
+	case a.allotted >= req.MinTimeout:
+	case req.Remaining >= req.MinTimeout:
+		a.allotted, a.floored = req.MinTimeout, true
+	default:
+		a.insufficient = true
+	}
+	return a
+}
+
+// timeout returns the timeout allotted, zero when the function is skipped
+func (a allotment) timeout() time.Duration {
+	if a.skipped || a.insufficient {
+		return 0
+	}
+	return a.allotted
+}
+
+// higher returns the dynamic context functions of a higher priority than p among ps
+// and their children
+func higher(ps []Proccess, p Priority) []*FunctionWithDynamiContext {
+	var fs []*FunctionWithDynamiContext
+	for _, q := range ps {
+		if f, ok := q.(*FunctionWithDynamiContext); ok && f.priority > p {
+			fs = append(fs, f)
+		}
+		if c, ok := q.(Composite); ok {
+			fs = append(fs, higher(c.Children(), p)...)
+		}
+	}
+	return fs
+}
+
+// totalWeight returns the sum of the weights of the dynamic context functions among ps
+// and their children
+func totalWeight(ps []Proccess) float64 {
+	var total float64
+	for _, p := range ps {
+		if f, ok := p.(*FunctionWithDynamiContext); ok {
+			total += f.weight
+		}
+		if c, ok := p.(Composite); ok {
+			total += totalWeight(c.Children())
+		}
+	}
+	return total
+}
+
+// declared returns the sum of the declared timeouts of ps and their children
+func declared(ps []Proccess) time.Duration {
+	var total time.Duration
+	for _, p := range ps {
+		switch p := p.(type) {
+		case *FunctionWithTimeout:
+			total += p.timeout
+		case *Delay:
+			total += time.Duration(p.delay) * time.Millisecond
+		}
+		if c, ok := p.(Composite); ok {
+			total += declared(c.Children())
+		}
+	}
+	return total
+}
+
+// planner is implemented by the strategies allotting the slices planned when the run
+// starts, the report of the run shows them next to the time consumed
+type planner interface {
+	AllocationStrategy
+	planner()
+}
+
+// plan denotes the slices of the budget planned for the dynamic context functions of a
+// run, see AllocationRequest.Planned
+type plan struct {
+	// budget is the budget left to the functions once the declared timeouts are taken
+	// out, and weights the sum of their weights
+	budget  time.Duration
+	weights float64
+}
+
+// newPlan returns the plan of the dynamic context functions among ps for budget
+func newPlan(ps []Proccess, budget time.Duration) plan {
+	return plan{budget: max(budget-declared(ps), 0), weights: totalWeight(ps)}
+}
+
+// slice returns the slice planned for weight, the weights are normalized when they sum
+// to more than 1
+func (p plan) slice(weight float64) time.Duration {
+	return time.Duration(float64(p.budget) * weight / max(p.weights, 1))
+}
+
+type planKey struct{}
+
+// withPlan returns a copy of ctx planning dynamic context functions with p
+func withPlan(ctx context.Context, p plan) context.Context {
+	return context.WithValue(ctx, planKey{}, p)
+}
+
+// planOf returns the plan of the run bound to ctx, the whole budget of ctx is planned
+// when there is none
+func planOf(ctx context.Context) plan {
+	if p, ok := ctx.Value(planKey{}).(plan); ok {
+		return p
+	}
+	return plan{budget: getBudget(ctx)}
+}
+
+type allocationKey struct{}
+
+// withAllocation returns a copy of ctx allotting dynamic context functions with st
+func withAllocation(ctx context.Context, st AllocationStrategy) context.Context {
+	return context.WithValue(ctx, allocationKey{}, st)
+}
+
+// allocationOf returns the strategy of the run bound to ctx, nil for the default one
+func allocationOf(ctx context.Context) AllocationStrategy {
+	st, _ := ctx.Value(allocationKey{}).(AllocationStrategy)
+	return st
+}
+
+// Weight returns the weight of the function
+func (f *FunctionWithDynamiContext) Weight() float64 {
+	return f.weight
+}
+
+// Priority returns the priority of the function
+func (f *FunctionWithDynamiContext) Priority() Priority {
+	return f.priority
+}
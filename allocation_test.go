@@ -0,0 +1,117 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+// timeouts runs ps on the virtual clock with a budget of ms and returns the timeouts of
+// the rows by name
+func timeouts(t *testing.T, ms int, st AllocationStrategy, ps ...Proccess) map[string]time.Duration {
+	t.Helper()
+	s := NewSimulator("allocation", ms, WithVirtualClock(), WithVerbosity(Quiet), WithAllocationStrategy(st))
+	if err := s.RegisterFunctions(ps...); err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]time.Duration)
+	for _, rec := range res.Records {
+		got[rec.Name] = rec.Timeout
+	}
+	return got
+}
+
+func expect(t *testing.T, got map[string]time.Duration, want map[string]time.Duration) {
+	t.Helper()
+	for name, d := range want {
+		if got[name] != d {
+			t.Errorf("%s: allotted %v, want %v", name, got[name], d)
+		}
+	}
+}
+
+func TestWeightedAllocation(t *testing.T) {
+	got := timeouts(t, 300, WeightedAllocation{},
+		NewFunction("a").WithDynamicContext(0.5, false),
+		NewSequentialGroup("g", NewFunction("b").WithDynamicContext(0.5, false), NewFunction("c").WithTimeout(25)),
+		NewFunction("d").WithDynamicContext(1, false),
+	)
+	expect(t, got, map[string]time.Duration{"a": 150 * time.Millisecond, "b": 75 * time.Millisecond, "d": 50 * time.Millisecond})
+}
+
+func TestEqualSplitAllocation(t *testing.T) {
+	got := timeouts(t, 300, EqualSplitAllocation{},
+		NewSequentialGroup("g",
+			NewFunction("a").WithDynamicContext(1, false),
+			NewFunction("b").WithDynamicContext(1, false),
+			NewFunction("c").WithDynamicContext(1, false),
+		),
+	)
+	expect(t, got, map[string]time.Duration{"a": 100 * time.Millisecond, "b": 100 * time.Millisecond, "c": 100 * time.Millisecond})
+}
+
+func TestPriorityFirstAllocation(t *testing.T) {
+	got := timeouts(t, 300, PriorityFirstAllocation{},
+		NewSequentialGroup("g",
+			NewFunction("a").WithDynamicContext(0.5, false),
+			NewFunction("b").WithDynamicContextPriority(0.6, PriorityCritical),
+		),
+		NewFunction("c").WithDynamicContextPriority(0.5, PriorityBestEffort),
+	)
+	// a leaves 60% of the budget to b, c is served last
+	expect(t, got, map[string]time.Duration{"a": 60 * time.Millisecond, "b": 144 * time.Millisecond, "c": 48 * time.Millisecond})
+}
+
+type fixedAllocation time.Duration
+
+func (f fixedAllocation) Allocate(req AllocationRequest) time.Duration {
+	return time.Duration(f)
+}
+
+func TestCustomAllocation(t *testing.T) {
+	got := timeouts(t, 300, fixedAllocation(40*time.Millisecond),
+		NewFunction("a").WithDynamicContext(0.5, false),
+		NewFunction("b").WithDynamicContext(0.1, false).WithMinTimeout(60),
+		NewFunction("c").WithDynamicContext(0.1, false).WithTimeoutBounds(0, 30),
+	)
+	expect(t, got, map[string]time.Duration{"a": 40 * time.Millisecond, "b": 60 * time.Millisecond, "c": 30 * time.Millisecond})
+}
+
+func TestProportionalAllocation(t *testing.T) {
+	s := NewSimulator("proportional", 300, WithVirtualClock(), WithVerbosity(Quiet), WithAllocationStrategy(ProportionalAllocation{}))
+	s.RegisterFunctions(
+		NewFunction("a").WithDynamicContext(0.6, false),
+		NewFunction("b").WithDynamicContext(0.6, false),
+		NewFunction("c").WithTimeout(10),
+	)
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Normalized != 1.2 {
+		t.Errorf("normalized %v, want 1.2", res.Normalized)
+	}
+	want := map[string]time.Duration{"a": 145 * time.Millisecond, "b": 145 * time.Millisecond, "c": 10 * time.Millisecond}
+	for _, rec := range res.Records {
+		if rec.Status() != StatusOK || rec.Timeout != want[rec.Name] {
+			t.Errorf("%s: %s after %v, want ok after %v", rec.Name, rec.Status(), rec.Timeout, want[rec.Name])
+		}
+		if rec.Name != "c" && rec.Planned != want[rec.Name] {
+			t.Errorf("%s: planned %v, want %v", rec.Name, rec.Planned, want[rec.Name])
+		}
+	}
+}
+
+func TestProportionalAllocationOrder(t *testing.T) {
+	// the slices do not depend on the order the functions run in
+	for _, ps := range [][]Proccess{
+		{NewFunction("a").WithDynamicContext(0.2, false), NewFunction("b").WithDynamicContext(0.6, false)},
+		{NewFunction("b").WithDynamicContext(0.6, false), NewFunction("a").WithDynamicContext(0.2, false)},
+	} {
+		got := timeouts(t, 300, &ProportionalAllocation{}, ps...)
+		expect(t, got, map[string]time.Duration{"a": 60 * time.Millisecond, "b": 180 * time.Millisecond})
+	}
+}
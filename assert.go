@@ -0,0 +1,76 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Assertion denotes a process checking an expectation on the remaining budget
+type Assertion struct {
+	name         string
+	minRemaining int64
+	passed       bool
+	isExecuted   flag
+}
+
+// Assert returns a process checking that at least minRemaining ms are left when it is
+// reached. A failed assertion does not stop the run, it is reported as an SLO violation.
+func Assert(name string, minRemaining int64) *Assertion {
+	return &Assertion{
+		name:         name,
+		minRemaining: minRemaining,
+	}
+}
+
+// Run checks the remaining budget of ctx, nothing is recorded when ctx has expired
+func (a *Assertion) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	remaining := getRemaining(ctx)
+	a.passed = remaining >= time.Duration(a.minRemaining)*time.Millisecond
+	a.isExecuted.set(true)
+	rec := Record{
+		Timeout: time.Duration(a.minRemaining) * time.Millisecond,
+		Failed:  !a.passed,
+		Note:    fmt.Sprintf("remaining %dms >= %dms", remaining.Milliseconds(), a.minRemaining),
+	}
+	if !a.passed {
+		rec.Violation = fmt.Sprintf("remaining %dms, expected at least %dms", remaining.Milliseconds(), a.minRemaining)
+		rec.Note = "violated: " + rec.Violation
+	}
+	sp.end(ctx, w, a.name, rec)
+}
+
+// Passed returns true if the last run met the expectation
+func (a *Assertion) Passed() bool {
+	return a.isExecuted.get() && a.passed
+}
+
+// Succeeded returns true if the last run met the expectation, so processes can depend on it
+func (a *Assertion) Succeeded() bool {
+	return a.Passed()
+}
+
+// IsExecuted returns true if the assertion has been checked
+func (a *Assertion) IsExecuted() bool {
+	return a.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (a *Assertion) ResetRun() {
+	a.isExecuted.set(false)
+	a.passed = false
+}
+
+func (a *Assertion) String() string {
+	return a.name
+}
+
+// Describe returns the expectation of the assertion
+func (a *Assertion) Describe() string {
+	return fmt.Sprintf("assert %dms left", a.minRemaining)
+}
@@ -0,0 +1,131 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// backgroundTimeout is the budget of the context background processes run against,
+// detached from the deadline of the run
+const backgroundTimeout = time.Minute
+
+// BackgroundProcess denotes a fire-and-forget process: it is dispatched to a goroutine
+// and does not count against the budget, apart from its dispatch cost
+type BackgroundProcess struct {
+	p            Proccess
+	dispatchCost int
+	isExecuted   flag
+}
+
+// Background returns a process dispatching p to the background, the run of the
+// simulator waits for it to finish unless WithAbandonBackground is set
+func Background(p Proccess) *BackgroundProcess {
+	return &BackgroundProcess{p: p, dispatchCost: 1}
+}
+
+// WithDispatchCost sets the time in ms consumed from the budget to dispatch the
+// process, 1ms by default. It panics when ms is negative.
+func (b *BackgroundProcess) WithDispatchCost(ms int) *BackgroundProcess {
+	if ms < 0 {
+		panic(fmt.Sprintf("t0simulator: background %q: negative dispatch cost %d", b.p.String(), ms))
+	}
+	b.dispatchCost = ms
+	return b
+}
+
+// Run dispatches the process and records a dispatched row once the dispatch cost is
+// consumed. The rows of the process are printed when the run of the simulator settles
+// its background processes.
+func (b *BackgroundProcess) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	bgCtx, cancel := withTimeout(context.WithoutCancel(ctx), backgroundTimeout)
+	heldCtx, held := hold(nested(bgCtx))
+	task := &backgroundTask{p: b.p, held: held, path: positionOf(ctx).path, done: make(chan struct{})}
+	if r, ok := ctx.Value(backgroundKey{}).(*backgrounds); ok {
+		r.add(task)
+	}
+	spawn(ctx, func() {
+		defer cancel()
+		runProcess(heldCtx, held.writer(), b.p)
+		close(task.done)
+	})
+
+	cost := time.Duration(b.dispatchCost) * time.Millisecond
+	if !pause(ctx, cost) {
+		return
+	}
+	b.isExecuted.set(true)
+	sp.end(ctx, w, b.String(), Record{Timeout: cost, Note: "dispatched"})
+}
+
+// IsExecuted returns true if the process has been dispatched
+func (b *BackgroundProcess) IsExecuted() bool {
+	return b.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run, along with the one of the process dispatched
+func (b *BackgroundProcess) ResetRun() {
+	b.isExecuted.set(false)
+	resetProcess(b.p)
+}
+
+func (b *BackgroundProcess) String() string {
+	return b.p.String()
+}
+
+// Describe returns the dispatch cost of the process
+func (b *BackgroundProcess) Describe() string {
+	return fmt.Sprintf("background, dispatch %dms", b.dispatchCost)
+}
+
+type backgroundKey struct{}
+
+// backgroundTask denotes a process dispatched to the background
+type backgroundTask struct {
+	p    Proccess
+	held *heldRecords
+	path []int
+	done chan struct{}
+}
+
+// backgrounds collects the background processes dispatched during a run
+type backgrounds struct {
+	mu     sync.Mutex
+	tasks  []*backgroundTask
+	closed bool
+}
+
+// add adds t to the run, tasks dispatched after the run settled are ignored
+func (b *backgrounds) add(t *backgroundTask) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.tasks = append(b.tasks, t)
+	}
+}
+
+// settle waits for the background processes when wait is true, releases the rows of
+// the finished ones to w and returns the records of the abandoned ones
+func (b *backgrounds) settle(ctx context.Context, w io.Writer, wait bool) []Record {
+	b.mu.Lock()
+	b.closed = true
+	tasks := b.tasks
+	b.mu.Unlock()
+
+	var abandoned []Record
+	for _, t := range tasks {
+		if wait {
+			await(ctx, func() bool { return closed(t.done) }, func() { <-t.done })
+		}
+		select {
+		case <-t.done:
+			t.held.release(w)
+		default:
+			abandoned = append(abandoned, Record{Name: t.p.String(), Depth: t.held.depth, Path: t.path, Start: -1, End: -1, Started: true, Note: "abandoned"})
+		}
+	}
+	return abandoned
+}
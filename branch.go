@@ -0,0 +1,127 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// Branching denotes a process running one of its children, picked at random with
+// a given probability on every run
+type Branching struct {
+	weights    []float64
+	children   []Proccess
+	taken      Proccess
+	random     random
+	isExecuted flag
+}
+
+// Branch returns a process running on every run a single child, the i-th child being
+// picked with a probability of weights[i]. Weights are normalized when they do not sum
+// to 1. It panics when the weights do not match the children or are not positive and finite.
+func Branch(weights []float64, children []Proccess) *Branching {
+	if len(children) == 0 {
+		panic("t0simulator: branch: no children")
+	}
+	if len(weights) != len(children) {
+		panic(fmt.Sprintf("t0simulator: branch: %d weights for %d children", len(weights), len(children)))
+	}
+	var total float64
+	for i, weight := range weights {
+		if !(weight > 0) || math.IsInf(weight, 1) {
+			panic(fmt.Sprintf("t0simulator: branch: weight %v of %q not positive and finite", weight, children[i]))
+		}
+		total += weight
+	}
+	normalized := make([]float64, len(weights))
+	for i, weight := range weights {
+		normalized[i] = weight / total
+	}
+	return &Branching{
+		weights:  normalized,
+		children: children,
+	}
+}
+
+// WithSource sets the source branches are picked from, the default source of math/rand is used otherwise
+func (b *Branching) WithSource(src rand.Source) *Branching {
+	b.random.setSource(src)
+	return b
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (b *Branching) WithSeed(seed int64) *Branching {
+	return b.WithSource(rand.NewSource(seed))
+}
+
+// pick returns the index of a child drawn according to the weights
+func (b *Branching) pick() int {
+	u := b.random.float64()
+	for i, weight := range b.weights {
+		if u < weight {
+			return i
+		}
+		u -= weight
+	}
+	return len(b.weights) - 1
+}
+
+// Run runs the picked child, its row is followed by the row of the branch telling which
+// child was taken. Nothing is recorded for the branch when ctx expires first.
+func (b *Branching) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	i := b.pick()
+	b.taken = b.children[i]
+	runProcess(nested(ctx), w, b.taken)
+	if ctx.Err() != nil {
+		return
+	}
+	b.isExecuted.set(true)
+	sp.end(ctx, w, b.String(), Record{
+		Timeout: since(ctx, sp.startedAt),
+		Failed:  !succeeded(b.taken),
+		Outcome: b.taken.String(),
+		Note:    fmt.Sprintf("branch %s (%.0f%%)", b.taken, b.weights[i]*100),
+	})
+}
+
+// Taken returns the child taken by the last run, nil before the first one
+func (b *Branching) Taken() Proccess {
+	return b.taken
+}
+
+// IsExecuted returns true if the picked child has finished
+func (b *Branching) IsExecuted() bool {
+	return b.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (b *Branching) ResetRun() {
+	b.isExecuted.set(false)
+	b.taken = nil
+}
+
+func (b *Branching) String() string {
+	names := make([]string, len(b.children))
+	for i, c := range b.children {
+		names[i] = c.String()
+	}
+	return strings.Join(names, " | ")
+}
+
+// Describe returns the normalized weights of the children
+func (b *Branching) Describe() string {
+	shares := make([]string, len(b.children))
+	for i, c := range b.children {
+		shares[i] = fmt.Sprintf("%s %.0f%%", c, b.weights[i]*100)
+	}
+	return "branch, " + strings.Join(shares, ", ")
+}
+
+// Children returns the children of the branch
+func (b *Branching) Children() []Proccess {
+	return b.children
+}
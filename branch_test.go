@@ -0,0 +1,48 @@
+package t0simulator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestBranchSeededPick(t *testing.T) {
+	a, b := NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(20)
+	br := Branch([]float64{1, 3}, []Proccess{a, b}).WithSeed(7)
+	s := NewSimulator("branch", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(br)
+	draws := rand.New(rand.NewSource(7))
+	for run := 1; run <= 20; run++ {
+		want, note := Proccess(b), "branch b (75%)"
+		if draws.Float64() < 0.25 {
+			want, note = a, "branch a (25%)"
+		}
+		res, err := s.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if br.Taken() != want {
+			t.Fatalf("run %d took %s, want %s", run, br.Taken(), want)
+		}
+		if len(res.Records) != 2 {
+			t.Fatalf("run %d: %d records, want the taken child and the branch", run, len(res.Records))
+		}
+		rec := res.ByName("a | b")[0]
+		if !rec.Executed || rec.Outcome != want.String() || rec.Note != note {
+			t.Errorf("run %d: branch record %+v, want outcome %s noted %q", run, rec, want, note)
+		}
+	}
+}
+
+func TestBranchInvalidWeights(t *testing.T) {
+	for _, weight := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("weight %v accepted", weight)
+				}
+			}()
+			Branch([]float64{1, weight}, []Proccess{NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(10)})
+		}()
+	}
+}
@@ -0,0 +1,158 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BreakerState denotes the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets calls through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails calls immediately until the cool-down is over
+	BreakerOpen
+	// BreakerHalfOpen lets a trial call through, closing the breaker on success
+	// and opening it again on failure
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// FunctionWithCircuitBreaker denotes a function simulation guarded by a circuit breaker,
+// the state of the breaker persists across runs
+type FunctionWithCircuitBreaker struct {
+	Function
+	latency     int
+	failureRate float64
+	threshold   int
+	coolDown    int
+	random      random
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openRuns int
+}
+
+// WithCircuitBreaker returns a simulated function of latency ms failing with probability
+// failureRate, guarded by a breaker opening after threshold consecutive failures. An open
+// breaker fails the next coolDown runs immediately, then half-opens. It panics when
+// failureRate is not in [0,1] or threshold or coolDown are lower than 1.
+func (f Function) WithCircuitBreaker(latency int, failureRate float64, threshold, coolDown int) *FunctionWithCircuitBreaker {
+	if failureRate < 0 || failureRate > 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: failure rate %v out of range [0,1]", f.name, failureRate))
+	}
+	if threshold < 1 || coolDown < 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: threshold %d or cool-down %d lower than 1", f.name, threshold, coolDown))
+	}
+	return &FunctionWithCircuitBreaker{
+		Function:    f,
+		latency:     latency,
+		failureRate: failureRate,
+		threshold:   threshold,
+		coolDown:    coolDown,
+	}
+}
+
+// WithSource sets the source failures are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithCircuitBreaker) WithSource(src rand.Source) *FunctionWithCircuitBreaker {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithCircuitBreaker) WithSeed(seed int64) *FunctionWithCircuitBreaker {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// Run runs the function when the breaker lets the call through, and fails it
+// immediately when the breaker is open. The row notes the state of the breaker
+// when the run started.
+func (f *FunctionWithCircuitBreaker) Run(ctx context.Context, w io.Writer) {
+	f.mu.Lock()
+	state := f.state
+	if state == BreakerOpen {
+		f.openRuns++
+		if f.openRuns >= f.coolDown {
+			f.state = BreakerHalfOpen
+		}
+	}
+	f.mu.Unlock()
+
+	if state == BreakerOpen {
+		f.sleep(ctx, w, 0, Record{Failed: true, Note: state.String()})
+		return
+	}
+
+	latency := time.Duration(f.latency) * time.Millisecond
+	failed := f.random.float64() < f.failureRate
+	f.mu.Lock()
+	switch {
+	case !failed:
+		f.state, f.failures = BreakerClosed, 0
+	case state == BreakerHalfOpen:
+		f.open()
+	default:
+		f.failures++
+		if f.failures >= f.threshold {
+			f.open()
+		}
+	}
+	f.mu.Unlock()
+	f.sleep(ctx, w, latency, Record{Timeout: latency, Failed: failed, Note: state.String()})
+}
+
+// open opens the breaker, f.mu must be held
+func (f *FunctionWithCircuitBreaker) open() {
+	f.state, f.failures, f.openRuns = BreakerOpen, 0, 0
+}
+
+// State returns the current state of the breaker
+func (f *FunctionWithCircuitBreaker) State() BreakerState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// Reset closes the breaker and forgets the failures seen so far
+func (f *FunctionWithCircuitBreaker) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.state, f.failures, f.openRuns = BreakerClosed, 0, 0
+}
+
+// ResetCounters closes the breaker, like Reset, so the runs after the warm-up of RunN
+// start from a closed breaker
+func (f *FunctionWithCircuitBreaker) ResetCounters() {
+	f.Reset()
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithCircuitBreaker) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithCircuitBreaker) String() string {
+	return f.name
+}
+
+// Describe returns the breaker configuration of the function
+func (f *FunctionWithCircuitBreaker) Describe() string {
+	return fmt.Sprintf("timeout %dms, failure rate %v, opens after %d failures for %d runs", f.latency, f.failureRate, f.threshold, f.coolDown)
+}
@@ -0,0 +1,41 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerAcrossRuns(t *testing.T) {
+	f := NewFunction("a").WithCircuitBreaker(20, 1, 2, 2)
+	s := NewSimulator("breaker", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(f)
+	// two failures open the breaker, which fails the next two runs immediately and
+	// half-opens, the failed trial call opens it again
+	for run, want := range []struct {
+		note     string
+		consumed time.Duration
+	}{
+		{"closed", 20 * time.Millisecond},
+		{"closed", 20 * time.Millisecond},
+		{"open", 0},
+		{"open", 0},
+		{"half-open", 20 * time.Millisecond},
+		{"open", 0},
+	} {
+		res, err := s.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := res.Records[0]
+		if !rec.Executed || !rec.Failed || rec.Note != want.note || rec.Consumed != want.consumed {
+			t.Errorf("run %d: record %+v, want a failure noted %q consuming %v", run+1, rec, want.note, want.consumed)
+		}
+	}
+	if f.State() != BreakerOpen {
+		t.Errorf("breaker %s, want open", f.State())
+	}
+	f.Reset()
+	if f.State() != BreakerClosed {
+		t.Errorf("breaker %s after a reset, want closed", f.State())
+	}
+}
@@ -0,0 +1,77 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// Cache outcomes of a function backed by a cache
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// FunctionWithCache denotes a function simulation backed by a cache, fast on a hit
+// and slow on a miss
+type FunctionWithCache struct {
+	Function
+	hitRate                 float64
+	hitLatency, missLatency int
+	random                  random
+}
+
+// WithCache returns a simulated function hitting its cache with probability hitRate,
+// it sleeps hitLatency ms on a hit and missLatency ms on a miss. It panics when hitRate
+// is not in [0,1] or a latency is negative.
+func (f Function) WithCache(hitRate float64, hitLatency, missLatency int) *FunctionWithCache {
+	if hitRate < 0 || hitRate > 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: hit rate %v out of range [0,1]", f.name, hitRate))
+	}
+	if hitLatency < 0 || missLatency < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative hit latency %d or miss latency %d", f.name, hitLatency, missLatency))
+	}
+	return &FunctionWithCache{
+		Function:    f,
+		hitRate:     hitRate,
+		hitLatency:  hitLatency,
+		missLatency: missLatency,
+	}
+}
+
+// WithSource sets the source hits are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithCache) WithSource(src rand.Source) *FunctionWithCache {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithCache) WithSeed(seed int64) *FunctionWithCache {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// Run runs the function, the row holds the cache outcome
+func (f *FunctionWithCache) Run(ctx context.Context, w io.Writer) {
+	outcome, latency := CacheMiss, f.missLatency
+	if f.random.float64() < f.hitRate {
+		outcome, latency = CacheHit, f.hitLatency
+	}
+	d := time.Duration(latency) * time.Millisecond
+	f.sleep(ctx, w, d, Record{Timeout: d, Outcome: outcome, Note: outcome})
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithCache) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithCache) String() string {
+	return f.name
+}
+
+// Describe returns the cache configuration of the function
+func (f *FunctionWithCache) Describe() string {
+	return fmt.Sprintf("hit rate %v, hit %dms, miss %dms", f.hitRate, f.hitLatency, f.missLatency)
+}
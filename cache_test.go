@@ -0,0 +1,28 @@
+package t0simulator
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestCacheOutcome(t *testing.T) {
+	f := NewFunction("a").WithCache(0.8, 5, 50).WithSeed(3)
+	s := NewSimulator("cache", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(f)
+	draws := rand.New(rand.NewSource(3))
+	for run := 1; run <= 20; run++ {
+		outcome, consumed := CacheMiss, 50*time.Millisecond
+		if draws.Float64() < 0.8 {
+			outcome, consumed = CacheHit, 5*time.Millisecond
+		}
+		res, err := s.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := res.Records[0]
+		if rec.Outcome != outcome || rec.Note != outcome || rec.Consumed != consumed {
+			t.Errorf("run %d: %s consuming %v, want %s consuming %v", run, rec.Outcome, rec.Consumed, outcome, consumed)
+		}
+	}
+}
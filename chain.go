@@ -0,0 +1,173 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ChainResult denotes the outcome of simulators chained on a single end-to-end budget
+type ChainResult struct {
+	Budget time.Duration
+	Stages []StageResult
+	// Consumed is the time consumed by all stages, and Remaining the time left of the
+	// end-to-end budget once they are done, zero when it ran out
+	Consumed  time.Duration
+	Remaining time.Duration
+	// Overdraft is the time consumed past the end-to-end budget
+	Overdraft time.Duration
+}
+
+// StageResult denotes the outcome of a single stage of a chain
+type StageResult struct {
+	Name string
+	// Available is the remaining end-to-end budget when the stage started, and Slice
+	// the part of it allotted to the stage
+	Available time.Duration
+	Slice     time.Duration
+	Weight    float64
+	Consumed  time.Duration
+	// Overdraft is the time the stage consumed past its slice, e.g. finishing its
+	// processes with FinishCurrent
+	Overdraft time.Duration
+	// Exceeded is true when the stage reached the deadline of its slice
+	Exceeded bool
+	// Result is the result of the run of the stage, nil when it never ran because the
+	// end-to-end budget was exhausted
+	Result *Result
+	Err    error
+}
+
+// Chain runs stages one after another on a single end-to-end budget of budget ms, e.g.
+// a gateway, a service and a worker a request goes through. Every stage runs against a
+// slice of the budget left by the previous ones, allotted like a dynamic context: its
+// weight set by WithChainWeight, or an even split of the budget left between the stages
+// left otherwise. The slice is capped by the budget of the stage. The error matches
+// ErrBudgetExceeded when a stage exceeded its slice or the budget ran out before the
+// last stage, and wraps the errors of the stages.
+func Chain(budget int, stages ...*Simulator) (*ChainResult, error) {
+	if budget <= 0 {
+		return nil, fmt.Errorf("%w: chain budget %d not positive", ErrInvalidConfig, budget)
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("%w: chain of no stage", ErrInvalidConfig)
+	}
+	for _, s := range stages {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	res := &ChainResult{Budget: time.Duration(budget) * time.Millisecond}
+	res.Remaining = res.Budget
+	var notRun, exceeded []string
+	var stageErrs []error
+	for i, s := range stages {
+		stage := StageResult{Name: s.name, Available: res.Remaining, Weight: s.chainWeight}
+		if stage.Weight == 0 {
+			stage.Weight = 1 / float64(len(stages)-i)
+		}
+		if res.Remaining <= 0 {
+			res.Stages = append(res.Stages, stage)
+			notRun = append(notRun, s.name)
+			continue
+		}
+		stage.Slice = allot(res.Remaining, stage.Weight, PriorityNormal, 0, 0, 0).allotted
+		stage.Result, stage.Err = s.RunContext(withSlice(context.Background(), stage.Slice))
+		if stage.Result != nil {
+			stage.Consumed = stage.Result.Summary.Consumed
+			stage.Overdraft = max(stage.Consumed-stage.Slice, 0)
+			stage.Exceeded = stage.Result.TimedOut || stage.Overdraft > 0
+		}
+		if stage.Exceeded {
+			exceeded = append(exceeded, s.name)
+		}
+		if stage.Err != nil {
+			stageErrs = append(stageErrs, fmt.Errorf("stage %q: %w", s.name, stage.Err))
+		}
+		res.Consumed += stage.Consumed
+		res.Remaining = max(res.Remaining-stage.Consumed, 0)
+		res.Stages = append(res.Stages, stage)
+	}
+	res.Overdraft = max(res.Consumed-res.Budget, 0)
+	err := errors.Join(stageErrs...)
+	if len(exceeded) > 0 || len(notRun) > 0 {
+		err = errors.Join(&BudgetExceededError{Unexecuted: append(exceeded, notRun...)}, err)
+	}
+	return res, err
+}
+
+type sliceKey struct{}
+
+// withSlice returns a child of ctx capping the budget of the run of a stage to slice,
+// without a deadline so that the stage runs on its own clock
+func withSlice(ctx context.Context, slice time.Duration) context.Context {
+	return context.WithValue(ctx, sliceKey{}, slice)
+}
+
+// sliceOf returns the slice of the stage bound to ctx, if any
+func sliceOf(ctx context.Context) (time.Duration, bool) {
+	slice, ok := ctx.Value(sliceKey{}).(time.Duration)
+	return slice, ok
+}
+
+// Exceeded returns the names of the stages that exceeded their slice
+func (c *ChainResult) Exceeded() []string {
+	var names []string
+	for _, s := range c.Stages {
+		if s.Exceeded {
+			names = append(names, s.Name)
+		}
+	}
+	return names
+}
+
+// WriteTable writes the report of every stage that ran to w, followed by the
+// end-to-end summary of the chain
+func (c *ChainResult) WriteTable(w io.Writer) error {
+	names := make([]string, len(c.Stages))
+	for i, s := range c.Stages {
+		names[i] = s.Name
+		if s.Result != nil {
+			if err := s.Result.WriteTable(w); err != nil {
+				return err
+			}
+		}
+	}
+	tw := newTabWriter(w, defaultTabConfig)
+	fmt.Fprint(tw, "=====================\n")
+	fmt.Fprintf(tw, "CHAIN:%s\n", strings.Join(names, " -> "))
+	fmt.Fprint(tw, "Name\tSlice(ms)\tConsumed(ms)\tRemaining(ms)\tStatus\t\n")
+	fmt.Fprintf(tw, "Init\t%s\t\t%s\t\t\n", ms(c.Budget), ms(c.Budget))
+	remaining := c.Budget
+	for _, s := range c.Stages {
+		status := "ok"
+		switch {
+		case s.Result == nil:
+			fmt.Fprintf(tw, "%s\t\t\t\tnot run\t\n", s.Name)
+			continue
+		case s.Exceeded:
+			status = "exceeded"
+		}
+		remaining = max(remaining-s.Consumed, 0)
+		if s.Overdraft > 0 {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\toverdraft %s ms\t\n", s.Name, ms(s.Slice), ms(s.Consumed), ms(remaining), status, ms(s.Overdraft))
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", s.Name, ms(s.Slice), ms(s.Consumed), ms(remaining), status)
+	}
+	for _, s := range c.Stages {
+		if s.Exceeded {
+			fmt.Fprintf(tw, "Exceeded its slice: %s (%s ms of %s ms left)\n", s.Name, ms(s.Slice), ms(s.Available))
+		}
+	}
+	if c.Overdraft > 0 {
+		fmt.Fprintf(tw, "End to end: consumed %s ms of %s ms, overdraft %s ms\n", ms(c.Consumed), ms(c.Budget), ms(c.Overdraft))
+	} else {
+		fmt.Fprintf(tw, "End to end: consumed %s ms of %s ms, time left %s ms\n", ms(c.Consumed), ms(c.Budget), ms(c.Remaining))
+	}
+	fmt.Fprint(tw, "=====================\n")
+	return tw.Flush()
+}
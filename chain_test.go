@@ -0,0 +1,28 @@
+package t0simulator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestChainStageOverdraft(t *testing.T) {
+	gateway := NewSimulator("gateway", 1000, WithVirtualClock(), WithVerbosity(Quiet), WithChainWeight(0.5), WithDeadlinePolicy(FinishCurrent))
+	gateway.RegisterFunctions(NewFunction("auth").WithTimeout(80))
+	service := NewSimulator("service", 1000, WithVirtualClock(), WithVerbosity(Quiet))
+	service.RegisterFunctions(NewFunction("query").WithTimeout(10))
+	res, err := Chain(100, gateway, service)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("chain returned %v, want the budget exceeded", err)
+	}
+	g, s := res.Stages[0], res.Stages[1]
+	if g.Slice != 50*time.Millisecond || g.Consumed != 80*time.Millisecond || g.Overdraft != 30*time.Millisecond || !g.Exceeded {
+		t.Errorf("gateway slice %v, consumed %v, overdraft %v, exceeded %v", g.Slice, g.Consumed, g.Overdraft, g.Exceeded)
+	}
+	if s.Available != 20*time.Millisecond || s.Consumed != 10*time.Millisecond || s.Exceeded {
+		t.Errorf("service available %v, consumed %v, exceeded %v", s.Available, s.Consumed, s.Exceeded)
+	}
+	if res.Consumed != 90*time.Millisecond || res.Remaining != 10*time.Millisecond || res.Overdraft != 0 {
+		t.Errorf("chain consumed %v, remaining %v, overdraft %v", res.Consumed, res.Remaining, res.Overdraft)
+	}
+}
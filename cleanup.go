@@ -0,0 +1,64 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// WithCancellationCost returns a copy of the function consuming ms more when the deadline
+// of the run interrupts it, e.g. to close bodies or roll back transactions, before it
+// reports. It is set before picking the kind of function:
+//
+//	t0simulator.NewFunction("Save to DB").WithCancellationCost(20).WithTimeout(300)
+func (f Function) WithCancellationCost(ms int) Function {
+	f.cancellationCost = time.Duration(ms) * time.Millisecond
+	return f
+}
+
+// guardCleanup makes the run bound to ctx wait for the cleanup of the function
+// before reporting, the returned function must be called once it is done
+func (f *Function) guardCleanup(ctx context.Context) func() {
+	r, ok := ctx.Value(recorderKey{}).(*recorder)
+	if f.cancellationCost <= 0 || !ok || !r.addCleanup() {
+		return func() {}
+	}
+	return r.cleanups.done
+}
+
+// cleanup consumes the cancellation cost of the function interrupted by the deadline of
+// ctx, and records its row as failed. Nothing is done when the function has no
+// cancellation cost or was started after the deadline.
+func (f *Function) cleanup(ctx context.Context, w io.Writer, sp span, done func(), rec Record) {
+	if f.cancellationCost <= 0 || sp.before <= 0 {
+		return
+	}
+	sleep(ctx, f.cancellationCost)
+	done()
+	rec.Failed = true
+	rec.Cleanup = f.cancellationCost
+	rec.Note = fmt.Sprintf("cleanup %dms", f.cancellationCost.Milliseconds())
+	f.finish(ctx, w, sp, rec)
+}
+
+// addCleanup adds a pending cleanup to the run, it returns false once the run stopped
+// waiting for cleanups
+func (r *recorder) addCleanup() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.settled {
+		return false
+	}
+	r.cleanups.add()
+	return true
+}
+
+// waitCleanups waits for the pending cleanups of the run bound to ctx, cleanups
+// starting afterwards are not waited for
+func (r *recorder) waitCleanups(ctx context.Context) {
+	r.mu.Lock()
+	r.settled = true
+	r.mu.Unlock()
+	r.cleanups.wait(ctx)
+}
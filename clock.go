@@ -0,0 +1,493 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock denotes the source of time of a run, the real clock by default
+type Clock interface {
+	Now() time.Time
+	// NewTimer returns a timer sending the time on its channel once d has elapsed
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer denotes a single event of a clock
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false when it already fired
+	Stop() bool
+}
+
+// realClock is the clock of the wall-clock mode
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// scaledClock is the clock of the time scale mode: it runs factor times faster than the
+// wall clock, so the durations it reports are in the original scale
+type scaledClock struct {
+	start  time.Time
+	factor float64
+}
+
+func (c scaledClock) Now() time.Time {
+	return c.start.Add(time.Duration(float64(time.Since(c.start)) * c.factor))
+}
+
+// NewTimer returns a wall-clock timer of d compressed by the factor, to the nanosecond
+// so that short durations do not round down to zero
+func (c scaledClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(max(time.Duration(float64(d)/c.factor), 1))}
+}
+
+type clockKey struct{}
+
+// withClock returns a child of ctx whose processes take the time from c
+func withClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// clockOf returns the clock of the run bound to ctx, the real clock when there is none
+func clockOf(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return realClock{}
+}
+
+// now returns the current time of the run bound to ctx
+func now(ctx context.Context) time.Time {
+	return clockOf(ctx).Now()
+}
+
+// since returns the time elapsed since t in the run bound to ctx
+func since(ctx context.Context, t time.Time) time.Duration {
+	return now(ctx).Sub(t)
+}
+
+// sleep blocks for d on the clock of ctx, regardless of the deadline of ctx
+func sleep(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := clockOf(ctx).NewTimer(d)
+	await(ctx, func() bool { return fired(t) }, func() { <-t.C() })
+}
+
+// tracker is implemented by the clocks that advance once every goroutine of the run is
+// blocked on them, see virtualClock
+type tracker interface {
+	// spawned counts a new goroutine of the run, and exited one that returned
+	spawned()
+	exited()
+	// block marks the calling goroutine blocked until ready returns true, the returned
+	// function marks it running again. ready is called with the clock locked.
+	block(ready func() bool) (unblock func())
+}
+
+// spawn runs f in a new goroutine of the run bound to ctx
+func spawn(ctx context.Context, f func()) {
+	t, ok := clockOf(ctx).(tracker)
+	if !ok {
+		go f()
+		return
+	}
+	t.spawned()
+	go func() {
+		defer t.exited()
+		f()
+	}()
+}
+
+// await calls wait, which blocks until ready returns true, with the calling goroutine
+// blocked on the clock of ctx meanwhile. ready must not block.
+func await(ctx context.Context, ready func() bool, wait func()) {
+	if t, ok := clockOf(ctx).(tracker); ok {
+		defer t.block(ready)()
+	}
+	wait()
+}
+
+// fired returns true if t is a timer of a fake clock that fired, the clock must be locked
+func fired(t Timer) bool {
+	ft, ok := t.(*fakeTimer)
+	return ok && ft.fired
+}
+
+// closed returns true if ch is closed, ch must only ever be closed
+func closed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitGroup is a sync.WaitGroup whose waiters are blocked on the clock of the run
+type waitGroup struct {
+	wg sync.WaitGroup
+	n  atomic.Int64
+}
+
+func (g *waitGroup) add() {
+	g.n.Add(1)
+	g.wg.Add(1)
+}
+
+func (g *waitGroup) done() {
+	g.n.Add(-1)
+	g.wg.Done()
+}
+
+// spawn runs f in a new goroutine of the run bound to ctx, waited for by wait
+func (g *waitGroup) spawn(ctx context.Context, f func()) {
+	g.add()
+	spawn(ctx, func() {
+		defer g.done()
+		f()
+	})
+}
+
+// wait blocks until the goroutines of g are done
+func (g *waitGroup) wait(ctx context.Context) {
+	await(ctx, func() bool { return g.n.Load() == 0 }, g.wg.Wait)
+}
+
+// withTimeout is context.WithTimeout with a deadline expiring on the clock of ctx
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	c := clockOf(ctx)
+	if _, ok := c.(realClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	deadline := c.Now().Add(d)
+	if parent, ok := ctx.Deadline(); ok && !parent.After(deadline) {
+		// like context.WithDeadline, the deadline of the parent applies, and only
+		// expires the context when the parent enforces it
+		inner, cancel := context.WithCancel(ctx)
+		return &clockContext{Context: inner, deadline: parent}, cancel
+	}
+	inner, cancel := context.WithCancelCause(ctx)
+	if fc, ok := c.(interface{ fake() *FakeClock }); ok {
+		// the deadline expires as the fake clock reaches it, without a goroutine
+		t := fc.fake().afterFunc(deadline.Sub(c.Now()), func() { cancel(context.DeadlineExceeded) })
+		return &clockContext{Context: inner, deadline: deadline}, func() {
+			t.Stop()
+			cancel(context.Canceled)
+		}
+	}
+	t := c.NewTimer(deadline.Sub(c.Now()))
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-t.C():
+			cancel(context.DeadlineExceeded)
+		case <-inner.Done():
+			t.Stop()
+		}
+	}()
+	// cancelling waits for the timer to be stopped, so no goroutine outlives the context
+	return &clockContext{Context: inner, deadline: deadline}, func() {
+		cancel(context.Canceled)
+		<-stopped
+	}
+}
+
+// clockContext is a context whose deadline expires on a clock other than the real one
+type clockContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c *clockContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *clockContext) Err() error {
+	err := c.Context.Err()
+	if err != nil && errors.Is(context.Cause(c.Context), context.DeadlineExceeded) {
+		return context.DeadlineExceeded
+	}
+	return err
+}
+
+// FakeClock is a clock whose time only moves when advanced, to run simulations
+// deterministically in tests. Timers due at the same time fire in the order they
+// were created, before the deadlines of the contexts of the run due then, so a call
+// ending on a deadline finishes in time.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	seq    uint64
+}
+
+// NewFakeClock returns a fake clock set to start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer firing once the clock is advanced by d
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.add(&fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)})
+}
+
+// afterFunc returns a timer calling f, with the clock locked, once the clock is
+// advanced by d. It fires after the timers due at the same time.
+func (c *FakeClock) afterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.add(&fakeTimer{at: c.now.Add(d), f: f})
+}
+
+// add schedules t, it fires right away when it is due, the clock must be locked
+func (c *FakeClock) add(t *fakeTimer) *fakeTimer {
+	c.seq++
+	t.clock, t.seq = c, c.seq
+	if !t.at.After(c.now) {
+		t.fire(c.now)
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+func (c *FakeClock) fake() *FakeClock {
+	return c
+}
+
+// Advance moves the clock forward by d, firing the timers due in between
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for len(c.timers) > 0 && !c.next().at.After(target) {
+		c.fire()
+	}
+	c.now = target
+}
+
+// Waiters returns the number of timers yet to fire, e.g. to wait for a run to block
+// on the clock before advancing it
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// next returns the timer due first, the clock must be locked. Timers due at the same
+// time fire before deadlines, and deadlines from the innermost context to the outermost.
+func (c *FakeClock) next() *fakeTimer {
+	sort.Slice(c.timers, func(i, j int) bool {
+		a, b := c.timers[i], c.timers[j]
+		switch {
+		case !a.at.Equal(b.at):
+			return a.at.Before(b.at)
+		case (a.f == nil) != (b.f == nil):
+			return a.f == nil
+		case a.f != nil:
+			return a.seq > b.seq
+		}
+		return a.seq < b.seq
+	})
+	return c.timers[0]
+}
+
+// fire moves the clock to the timer due first and fires it, the clock must be locked
+func (c *FakeClock) fire() {
+	t := c.next()
+	c.timers = c.timers[1:]
+	c.now = t.at
+	t.fire(t.at)
+}
+
+// fakeTimer is a timer of a fake clock, it calls f instead of sending on c when f is set
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	seq   uint64
+	c     chan time.Time
+	f     func()
+	// fired is true once the timer fired, guarded by the mutex of the clock
+	fired bool
+}
+
+// fire fires the timer at now, the clock must be locked
+func (t *fakeTimer) fire(now time.Time) {
+	t.fired = true
+	if t.f != nil {
+		t.f()
+		return
+	}
+	t.c <- now
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// virtualClock is the clock of the virtual mode: no time elapses until every goroutine of
+// the run is blocked on it, it then jumps to the next timer. The goroutines are started
+// with spawn and block with await, any other goroutine of the run, e.g. one doing real
+// work, holds the clock until it returns.
+type virtualClock struct {
+	*FakeClock
+	stop    chan struct{}
+	stopped chan struct{}
+	// kick wakes the advancer up when goroutines block or exit
+	kick chan struct{}
+	// held is true while the run is paused, running is the number of goroutines of the
+	// run and blocked the readiness of the ones blocked on the clock, all guarded by the
+	// mutex of the clock
+	held    bool
+	running int
+	blocked map[*func() bool]struct{}
+}
+
+// newVirtualClock returns a virtual clock starting at start, it advances until stopped.
+// The calling goroutine is the first goroutine of the run.
+func newVirtualClock(start time.Time) *virtualClock {
+	c := &virtualClock{
+		FakeClock: NewFakeClock(start),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+		kick:      make(chan struct{}, 1),
+		running:   1,
+		blocked:   make(map[*func() bool]struct{}),
+	}
+	go c.advance()
+	return c
+}
+
+// close stops advancing the clock, it returns once the clock stopped
+func (c *virtualClock) close() {
+	close(c.stop)
+	<-c.stopped
+}
+
+// hold stops advancing the clock until release
+func (c *virtualClock) hold() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.held = true
+}
+
+// release advances the clock again
+func (c *virtualClock) release() {
+	c.mu.Lock()
+	c.held = false
+	c.mu.Unlock()
+	c.wake()
+}
+
+func (c *virtualClock) spawned() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running++
+}
+
+func (c *virtualClock) exited() {
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	c.wake()
+}
+
+func (c *virtualClock) block(ready func() bool) func() {
+	key := &ready
+	c.mu.Lock()
+	c.blocked[key] = struct{}{}
+	c.mu.Unlock()
+	c.wake()
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.blocked, key)
+	}
+}
+
+// wake makes the advancer check whether the run is blocked
+func (c *virtualClock) wake() {
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
+
+// settled returns true when every goroutine of the run is blocked on the clock and none
+// of them is ready to go on, the clock must be locked
+func (c *virtualClock) settled() bool {
+	if c.held || len(c.timers) == 0 || len(c.blocked) < c.running {
+		return false
+	}
+	for ready := range c.blocked {
+		if (*ready)() {
+			return false
+		}
+	}
+	return true
+}
+
+// advance fires the timer due next every time the run is blocked on the clock, the
+// timers due at the same time fire one after another as the run blocks again
+func (c *virtualClock) advance() {
+	defer close(c.stopped)
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-c.kick:
+		}
+		c.mu.Lock()
+		for c.settled() {
+			c.fire()
+		}
+		c.mu.Unlock()
+	}
+}
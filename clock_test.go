@@ -0,0 +1,121 @@
+package t0simulator
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestVirtualClockRunsInstantly(t *testing.T) {
+	s := NewSimulator("virtual", 10000, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(4000),
+		NewFunction("b").WithTimeout(5000),
+	)
+	start := time.Now()
+	res, err := s.Run()
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("a 10s run took %v, want under 100ms", elapsed)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Remaining != time.Second {
+		t.Errorf("remaining %v, want 1s", res.Remaining)
+	}
+}
+
+// virtualReport returns the rows of a run of a mix of processes on the virtual clock
+func virtualReport() string {
+	s := NewSimulator("virtual", 10000, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(1000),
+		NewParallelGroup("p", NewFunction("p1").WithTimeout(500), NewFunction("p2").WithDynamicContext(0.2, false)),
+		NewBulkhead("bh", 1, NewFunction("q1").WithTimeout(100)),
+		Race(NewFunction("r1").WithTimeout(100), NewFunction("r2").WithTimeout(200)),
+		NewFallback(NewFunction("f1").WithTimeout(300), NewFunction("f2").WithTimeout(50), 100),
+		NewFunction("b").WithDynamicContext(0.5, false),
+		NewFunction("c").WithTimeout(10),
+	)
+	res, err := s.Run()
+	report := fmt.Sprint(err)
+	for _, rec := range res.Records {
+		report += fmt.Sprintf("\n%s %v %v %s", rec.Name, rec.Timeout, rec.Remaining, rec.Status())
+	}
+	return report
+}
+
+func TestVirtualClockConcurrentRuns(t *testing.T) {
+	want := virtualReport()
+	var wg sync.WaitGroup
+	reports := make([]string, 200)
+	for i := range reports {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = virtualReport()
+		}()
+	}
+	wg.Wait()
+	for i, got := range reports {
+		if got != want {
+			t.Fatalf("run %d reported\n%s\nwant\n%s", i, got, want)
+		}
+	}
+}
+
+func TestVirtualClockCallEndingOnDeadline(t *testing.T) {
+	for _, f := range []Proccess{
+		NewFunction("whole").WithDynamicContext(1, false),
+		NewFunction("escalated").WithDynamicContext(0.1, true),
+	} {
+		s := NewSimulator("tie", 100, WithVirtualClock(), WithVerbosity(Quiet))
+		s.RegisterFunctions(NewFunction("a").WithTimeout(80), f)
+		res, err := s.Run()
+		if err != nil {
+			t.Fatalf("%s: %v", f, err)
+		}
+		if rec := res.Records[1]; rec.Status() != StatusOK || rec.Timeout != 20*time.Millisecond {
+			t.Errorf("%s: %s after %v, want ok after 20ms", f, rec.Status(), rec.Timeout)
+		}
+	}
+}
+
+func TestFakeClockPrintsExactRemaining(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var out syncBuffer
+	s := NewSimulator("fake", 100, WithClock(clock), WithOutput(&out))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewFunction("b").WithTimeout(50),
+		NewFunction("c").WithTimeout(40),
+	)
+	done := make(chan error)
+	go func() {
+		_, err := s.Run()
+		done <- err
+	}()
+	// every step waits for the call in progress to block on the clock, next to the deadline
+	for _, d := range []time.Duration{30, 50, 20} {
+		for clock.Waiters() < 2 {
+			runtime.Gosched()
+		}
+		clock.Advance(d * time.Millisecond)
+	}
+	if err := <-done; !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("run returned %v, want the budget exceeded", err)
+	}
+	for _, row := range []string{
+		"a    |30              |70            |ok     |\n",
+		"b    |50              |20            |ok     |\n",
+		"Interrupted: c after 20ms of 40ms (started, not finished)\n",
+	} {
+		if !strings.Contains(out.String(), row) {
+			t.Errorf("report misses %q\n%s", row, out.String())
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package t0simulator
+
+// Cloner is implemented by processes that can be copied, Clone returns a process with
+// the same configuration and a fresh state, as if it never ran. The children of
+// composite processes are copied along. Sources set with WithSource are not copied, the
+// copies draw from the default source of math/rand unless their simulator is seeded.
+type Cloner interface {
+	Clone() Proccess
+}
+
+// cloner copies processes, every process and pool is copied once so that the copies of
+// the processes depending on it, or sharing it, point to its copy
+type cloner struct {
+	processes map[Proccess]Proccess
+	pools     map[*Pool]*Pool
+	// functions holds the copies whose dependencies are mapped to their copies by finish
+	functions []*Function
+}
+
+func newCloner() *cloner {
+	return &cloner{processes: make(map[Proccess]Proccess), pools: make(map[*Pool]*Pool)}
+}
+
+// cloneProcess returns a copy of p along with its children
+func cloneProcess(p Proccess) Proccess {
+	c := newCloner()
+	q := c.process(p)
+	c.finish()
+	return q
+}
+
+// process returns the copy of p. Custom processes are copied with their Clone method,
+// the ones that do not implement Cloner are shared.
+func (c *cloner) process(p Proccess) Proccess {
+	if p == nil {
+		return nil
+	}
+	if q, ok := c.processes[p]; ok {
+		return q
+	}
+	q := p
+	switch v := p.(type) {
+	case interface{ clone(c *cloner) Proccess }:
+		q = v.clone(c)
+	case Cloner:
+		q = v.Clone()
+	}
+	c.processes[p] = q
+	return q
+}
+
+// processes returns the copies of ps
+func (c *cloner) all(ps []Proccess) []Proccess {
+	if ps == nil {
+		return nil
+	}
+	copies := make([]Proccess, len(ps))
+	for i, p := range ps {
+		copies[i] = c.process(p)
+	}
+	return copies
+}
+
+// function returns a copy of the configuration of f, dst must be the Function embedded
+// in the copy of the process
+func (c *cloner) function(dst *Function, f *Function) {
+	*dst = f.configuration()
+	c.functions = append(c.functions, dst)
+}
+
+// configuration returns a Function with the configuration of f and a fresh state, its
+// dependencies are not shared with f
+func (f *Function) configuration() Function {
+	return Function{
+		name:             f.name,
+		deps:             append([]Proccess(nil), f.deps...),
+		cancellationCost: f.cancellationCost,
+		onTimeout:        f.onTimeout,
+	}
+}
+
+// sampler returns the copy of l when it is a process or a histogram, l is shared otherwise
+func (c *cloner) sampler(l LatencySampler) LatencySampler {
+	switch v := l.(type) {
+	case Proccess:
+		if q, ok := c.process(v).(LatencySampler); ok {
+			return q
+		}
+	case *HistogramSampler:
+		return &HistogramSampler{bounds: v.bounds, cumulative: v.cumulative}
+	}
+	return l
+}
+
+// pool returns the copy of p
+func (c *cloner) pool(p *Pool) *Pool {
+	if p == nil {
+		return nil
+	}
+	if q, ok := c.pools[p]; ok {
+		return q
+	}
+	q := NewPool(p.slots.limit)
+	c.pools[p] = q
+	return q
+}
+
+// finish points the dependencies of the copies to the copies of the processes they
+// depend on, the ones that were not copied are kept
+func (c *cloner) finish() {
+	for _, f := range c.functions {
+		for i, dep := range f.deps {
+			if q, ok := c.processes[dep]; ok {
+				f.deps[i] = q
+			}
+		}
+	}
+}
+
+// Clone returns a simulator named name with the configuration of s and a copy of its
+// processes, see Cloner, so the variants of a scenario can be tuned and run, even
+// concurrently, without affecting each other. opts are applied to the copy, e.g. to give
+// it its own output, hooks or logger, which are shared with s otherwise along with an
+// injected clock. It panics when an option is invalid. Custom processes that do not
+// implement Cloner are shared too, and must not run concurrently in both simulators.
+func (s *Simulator) Clone(name string, opts ...Option) *Simulator {
+	c, err := s.CloneE(name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// CloneE is like Clone, it returns an error when an option is invalid
+func (s *Simulator) CloneE(name string, opts ...Option) (*Simulator, error) {
+	s.running.Lock()
+	defer s.running.Unlock()
+	c := &Simulator{config: s.copy()}
+	c.name = name
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// copy returns a copy of the configuration with a copy of the processes, the series of
+// a seeded configuration starts over in the copy
+func (c *config) copy() config {
+	q := *c
+	q.middleware = append([]Middleware(nil), c.middleware...)
+	if c.seeding != nil {
+		q.seeding = &seeding{seed: c.seeding.seed}
+	}
+	cl := newCloner()
+	q.process = cl.all(c.process)
+	cl.finish()
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithTimeout) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithTimeout) clone(c *cloner) Proccess {
+	q := &FunctionWithTimeout{
+		timeout:          f.timeout,
+		jitter:           f.jitter,
+		failure:          f.failure,
+		skipIfOverBudget: f.skipIfOverBudget,
+		coldStart:        f.coldStart,
+	}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithBudgetShare) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithBudgetShare) clone(c *cloner) Proccess {
+	q := &FunctionWithBudgetShare{share: f.share}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithDynamiContext) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithDynamiContext) clone(c *cloner) Proccess {
+	q := &FunctionWithDynamiContext{
+		weight:     f.weight,
+		priority:   f.priority,
+		failure:    f.failure,
+		minTimeout: f.minTimeout,
+		maxTimeout: f.maxTimeout,
+		floor:      f.floor,
+	}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the delay, see Cloner
+func (d *Delay) Clone() Proccess {
+	return cloneProcess(d)
+}
+
+func (d *Delay) clone(c *cloner) Proccess {
+	q := &Delay{delay: d.delay}
+	c.function(&q.Function, &d.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithRandomLatency) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithRandomLatency) clone(c *cloner) Proccess {
+	q := &FunctionWithRandomLatency{min: f.min, max: f.max}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithNormalLatency) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithNormalLatency) clone(c *cloner) Proccess {
+	q := &FunctionWithNormalLatency{mean: f.mean, stddev: f.stddev}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithLatencyProfile) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithLatencyProfile) clone(c *cloner) Proccess {
+	q := &FunctionWithLatencyProfile{p50: f.p50, p95: f.p95, p99: f.p99, buckets: make(map[string]int)}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithSampledLatency) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithSampledLatency) clone(c *cloner) Proccess {
+	q := &FunctionWithSampledLatency{sampler: c.sampler(f.sampler)}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithCircuitBreaker) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithCircuitBreaker) clone(c *cloner) Proccess {
+	q := &FunctionWithCircuitBreaker{
+		latency:     f.latency,
+		failureRate: f.failureRate,
+		threshold:   f.threshold,
+		coolDown:    f.coolDown,
+	}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithCache) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithCache) clone(c *cloner) Proccess {
+	q := &FunctionWithCache{hitRate: f.hitRate, hitLatency: f.hitLatency, missLatency: f.missLatency}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithRetry) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithRetry) clone(c *cloner) Proccess {
+	q := &FunctionWithRetry{
+		latency:     f.latency,
+		failureRate: f.failureRate,
+		maxAttempts: f.maxAttempts,
+		backoff:     f.backoff,
+	}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, its pool is copied too, see Cloner
+func (f *FunctionWithPool) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithPool) clone(c *cloner) Proccess {
+	q := &FunctionWithPool{pool: c.pool(f.pool), serviceLatency: f.serviceLatency}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithQueue) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithQueue) clone(c *cloner) Proccess {
+	q := &FunctionWithQueue{wait: c.sampler(f.wait), serviceMs: f.serviceMs}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *FunctionWithStreaming) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *FunctionWithStreaming) clone(c *cloner) Proccess {
+	q := &FunctionWithStreaming{chunks: f.chunks, chunkLatency: f.chunkLatency}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the function, see Cloner
+func (f *Hedged) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *Hedged) clone(c *cloner) Proccess {
+	q := &Hedged{latency: c.sampler(f.latency), hedgeDelay: f.hedgeDelay, maxHedges: f.maxHedges}
+	c.function(&q.Function, &f.Function)
+	return q
+}
+
+// Clone returns a copy of the process, see Cloner
+func (p *HTTPProcess) Clone() Proccess {
+	return cloneProcess(p)
+}
+
+func (p *HTTPProcess) clone(c *cloner) Proccess {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q := &HTTPProcess{url: p.url, handler: p.handler, reuse: p.reuse}
+	c.function(&q.Function, &p.Function)
+	return q
+}
+
+// Clone returns a copy of the process, the function it calls is shared, see Cloner
+func (p *RealProcess) Clone() Proccess {
+	return cloneProcess(p)
+}
+
+func (p *RealProcess) clone(c *cloner) Proccess {
+	q := &RealProcess{fn: p.fn}
+	c.function(&q.Function, &p.Function)
+	return q
+}
+
+// Clone returns a copy of the assertion, see Cloner
+func (a *Assertion) Clone() Proccess {
+	return cloneProcess(a)
+}
+
+func (a *Assertion) clone(c *cloner) Proccess {
+	return &Assertion{name: a.name, minRemaining: a.minRemaining}
+}
+
+// Clone returns a copy of the group and of its members, see Cloner
+func (g *ParallelGroup) Clone() Proccess {
+	return cloneProcess(g)
+}
+
+func (g *ParallelGroup) clone(c *cloner) Proccess {
+	return &ParallelGroup{name: g.name, members: c.all(g.members)}
+}
+
+// Clone returns a copy of the group and of its children, see Cloner
+func (g *BulkheadGroup) Clone() Proccess {
+	return cloneProcess(g)
+}
+
+func (g *BulkheadGroup) clone(c *cloner) Proccess {
+	return &BulkheadGroup{name: g.name, limit: g.limit, children: c.all(g.children)}
+}
+
+// Clone returns a copy of the group and of its children, see Cloner
+func (g *SequentialGroup) Clone() Proccess {
+	return cloneProcess(g)
+}
+
+func (g *SequentialGroup) clone(c *cloner) Proccess {
+	return &SequentialGroup{name: g.name, children: c.all(g.children)}
+}
+
+// Clone returns a copy of the group and of its children, see Cloner
+func (g *ThrottledGroup) Clone() Proccess {
+	return cloneProcess(g)
+}
+
+func (g *ThrottledGroup) clone(c *cloner) Proccess {
+	return Throttled(g.limit, g.per, c.all(g.children)...)
+}
+
+// Clone returns a copy of the simulator process, its simulator is cloned, see Cloner
+func (p *simulatorProcess) Clone() Proccess {
+	return cloneProcess(p)
+}
+
+func (p *simulatorProcess) clone(c *cloner) Proccess {
+	return &simulatorProcess{s: p.s.Clone(p.s.name)}
+}
+
+// Clone returns a copy of the branching and of its children, see Cloner
+func (b *Branching) Clone() Proccess {
+	return cloneProcess(b)
+}
+
+func (b *Branching) clone(c *cloner) Proccess {
+	return &Branching{weights: b.weights, children: c.all(b.children)}
+}
+
+// Clone returns a copy of the conditional process, see Cloner
+func (p *Conditional) Clone() Proccess {
+	return cloneProcess(p)
+}
+
+func (p *Conditional) clone(c *cloner) Proccess {
+	return &Conditional{p: c.process(p.p), minBudget: p.minBudget, predicate: p.predicate}
+}
+
+// Clone returns a copy of the fallback and of its processes, see Cloner
+func (f *Fallback) Clone() Proccess {
+	return cloneProcess(f)
+}
+
+func (f *Fallback) clone(c *cloner) Proccess {
+	return &Fallback{primary: c.process(f.primary), secondary: c.process(f.secondary), primaryBudget: f.primaryBudget}
+}
+
+// Clone returns a copy of the race and of its processes, see Cloner
+func (r *Racing) Clone() Proccess {
+	return cloneProcess(r)
+}
+
+func (r *Racing) clone(c *cloner) Proccess {
+	return &Racing{a: c.process(r.a), b: c.process(r.b)}
+}
+
+// Clone returns a copy of the repeat and of its process, see Cloner
+func (r *Repeat) Clone() Proccess {
+	return cloneProcess(r)
+}
+
+func (r *Repeat) clone(c *cloner) Proccess {
+	return &Repeat{p: c.process(r.p), n: r.n}
+}
+
+// Clone returns a copy of the background process and of its process, see Cloner
+func (b *BackgroundProcess) Clone() Proccess {
+	return cloneProcess(b)
+}
+
+func (b *BackgroundProcess) clone(c *cloner) Proccess {
+	return &BackgroundProcess{p: c.process(b.p), dispatchCost: b.dispatchCost}
+}
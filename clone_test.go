@@ -0,0 +1,80 @@
+package t0simulator
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCloneConcurrentRuns(t *testing.T) {
+	base := NewSimulator("base", 100, WithVirtualClock())
+	base.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewSequentialGroup("g", NewFunction("b").WithTimeout(30)),
+	)
+	variants := map[string]struct {
+		extra  int
+		report string
+	}{
+		"fast": {20, "Done with time left 20 ms"},
+		"slow": {60, "Time out reached"},
+	}
+	outputs := make(map[string]*bytes.Buffer)
+	done := make(map[string]int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, v := range variants {
+		out := &bytes.Buffer{}
+		outputs[name] = out
+		c := base.Clone(name, WithOutput(out), WithOnProcessDone(func(string, int64, int64) {
+			mu.Lock()
+			done[name]++
+			mu.Unlock()
+		}))
+		c.AddFunction(NewFunction("c").WithTimeout(v.extra))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Run()
+		}()
+	}
+	wg.Wait()
+	for name, v := range variants {
+		report := outputs[name].String()
+		if !strings.Contains(report, "SIMULATOR:"+name+"\n") || !strings.Contains(report, v.report) {
+			t.Errorf("%s: report\n%s", name, report)
+		}
+	}
+	if done["fast"] != 3 || done["slow"] != 2 {
+		t.Errorf("done hooks %v, want 3 for fast and 2 for slow", done)
+	}
+	if n := len(base.Processes()); n != 2 {
+		t.Errorf("base has %d processes, want 2", n)
+	}
+}
+
+func TestCloneConfiguration(t *testing.T) {
+	s := NewSimulator("base", 100, WithVerbosity(Quiet), WithExecution(Parallel), WithDrift(), WithReservedBudget(10),
+		WithMaxWallTime(time.Second), WithChainWeight(0.5), WithWarmupRuns(2), WithTimeScale(2), WithPriorityThreshold(50),
+		WithDeadlinePolicy(FinishCurrent), WithUnit(UnitMicrosecond), WithTabWriter(2, 3, '.', false))
+	s.RegisterFunctions(NewFunction("a").WithTimeout(30))
+	c := s.Clone("copy")
+	if c.name != "copy" || len(c.process) != 1 || c.process[0] == s.process[0] {
+		t.Fatalf("clone %q of %d processes, want a copy of the process", c.name, len(c.process))
+	}
+	want, got := s.config, c.config
+	want.name, want.process, got.process = "copy", nil, nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clone configured %+v, want %+v", got, want)
+	}
+}
+
+func TestCloneInvalidOption(t *testing.T) {
+	s := NewSimulator("base", 100, WithVerbosity(Quiet))
+	if _, err := s.CloneE("copy", WithChainWeight(2)); err == nil {
+		t.Error("invalid option accepted")
+	}
+}
@@ -0,0 +1,26 @@
+package t0simulator
+
+import "fmt"
+
+// Start outcomes of a function with a cold start
+const (
+	StartCold = "cold"
+	StartWarm = "warm"
+)
+
+// WithColdStart adds firstCallLatency ms to the timeout of the first run of the function,
+// the following runs are warm. Only Reset of the function makes it cold again: ResetRun,
+// and so Simulator.Reset and every Run, keep it warm, while a copy made by Clone starts
+// cold. It panics when firstCallLatency is negative.
+func (f *FunctionWithTimeout) WithColdStart(firstCallLatency int) *FunctionWithTimeout {
+	if firstCallLatency < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative cold start latency %d", f.name, firstCallLatency))
+	}
+	f.coldStart = firstCallLatency
+	return f
+}
+
+// Reset restores the cold state of the function, so the next run is a cold start
+func (f *FunctionWithTimeout) Reset() {
+	f.warm.set(false)
+}
@@ -0,0 +1,76 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Conditional denotes a process running the process it wraps only when a condition
+// on the remaining budget holds
+type Conditional struct {
+	p         Proccess
+	minBudget int
+	predicate func(remaining int64) bool
+	skipped   flag
+}
+
+// NewConditional returns a process running p only when at least minBudget ms are left
+func NewConditional(p Proccess, minBudget int) *Conditional {
+	c := &Conditional{p: p, minBudget: minBudget}
+	c.predicate = func(remaining int64) bool {
+		return remaining >= int64(c.minBudget)
+	}
+	return c
+}
+
+// NewConditionalFunc returns a process running p only when predicate returns true
+// for the remaining budget in ms
+func NewConditionalFunc(p Proccess, predicate func(remaining int64) bool) *Conditional {
+	return &Conditional{p: p, minBudget: -1, predicate: predicate}
+}
+
+// Run runs the wrapped process, or records a skipped row when the condition does not hold
+func (c *Conditional) Run(ctx context.Context, w io.Writer) {
+	c.skipped.set(false)
+	remaining := getRemaining(ctx)
+	if c.predicate(remaining.Milliseconds()) {
+		runProcess(ctx, w, c.p)
+		return
+	}
+	c.skipped.set(true)
+	skip(ctx, w, c.p.String(), Record{})
+}
+
+// IsExecuted returns true if the wrapped process has been executed or skipped, so a
+// skipped process is never reported as unexecuted
+func (c *Conditional) IsExecuted() bool {
+	return c.skipped.get() || c.p.IsExecuted()
+}
+
+// ResetRun clears the state of the last run, along with the one of the wrapped process
+func (c *Conditional) ResetRun() {
+	c.skipped.set(false)
+	resetProcess(c.p)
+}
+
+// IsSkipped returns true if the last run skipped the wrapped process
+func (c *Conditional) IsSkipped() bool {
+	return c.skipped.get()
+}
+
+func (c *Conditional) String() string {
+	return c.p.String()
+}
+
+// Describe returns the condition, followed by the description of the wrapped process
+func (c *Conditional) Describe() string {
+	cond := "if predicate"
+	if c.minBudget >= 0 {
+		cond = fmt.Sprintf("if %dms left", c.minBudget)
+	}
+	if d, ok := c.p.(Describer); ok {
+		return cond + ", " + d.Describe()
+	}
+	return cond
+}
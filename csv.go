@@ -0,0 +1,40 @@
+package t0simulator
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ms returns d as a whole number of milliseconds
+func ms(d time.Duration) string {
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+// WriteCSV writes the report rows as CSV to w, starting with a header row and
+// ending with a summary row for the overall outcome. The processes interrupted or
+// never started by the deadline have a row too, told apart by their status.
+func (r *Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{
+		{"name", "max_timeout_ms", "remaining_ms", "status"},
+		{"Init", ms(r.Budget), ms(r.Budget), ""},
+	}
+	for _, rec := range r.Records {
+		rows = append(rows, []string{rec.Name, ms(rec.Timeout), ms(rec.Remaining), string(rec.Status())})
+	}
+	outcome := "Done"
+	switch {
+	case r.TimedOut:
+		outcome = "Time out"
+	case r.Cancelled:
+		outcome = "Cancelled"
+	}
+	rows = append(rows, []string{outcome, "", ms(r.Remaining), ""})
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
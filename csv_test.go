@@ -0,0 +1,26 @@
+package t0simulator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSVTimeOut(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("csv", 100, WithVirtualClock(), WithOutput(&out), WithFormat(FormatCSV))
+	s.RegisterFunctions(
+		NewFunction("a, b").WithTimeout(30),
+		NewFunction("slow").WithTimeout(200),
+		NewFunction("c").WithTimeout(10),
+	)
+	s.Run()
+	want := "name,max_timeout_ms,remaining_ms,status\n" +
+		"Init,100,100,\n" +
+		"\"a, b\",30,70,ok\n" +
+		"slow,200,0,in progress\n" +
+		"c,10,0,unexecuted\n" +
+		"Time out,,0,\n"
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Delay denotes a process simulating an overhead that is not a call to a dependency,
+// e.g. serialization, template rendering or scheduler delay
+type Delay struct {
+	Function
+	delay int
+}
+
+// NewDelay returns a process sleeping ms, its row is tagged as overhead
+func NewDelay(name string, ms int) *Delay {
+	return &Delay{
+		Function: NewFunction(name),
+		delay:    ms,
+	}
+}
+
+// Run runs the delay
+func (d *Delay) Run(ctx context.Context, w io.Writer) {
+	delay := time.Duration(d.delay) * time.Millisecond
+	d.sleep(ctx, w, delay, Record{Timeout: delay, Overhead: true, Note: "overhead"})
+}
+
+// IsExecuted returns true if the delay has elapsed
+func (d *Delay) IsExecuted() bool {
+	return d.isExecuted.get()
+}
+
+func (d *Delay) String() string {
+	return d.name
+}
+
+// Describe returns the duration of the delay
+func (d *Delay) Describe() string {
+	return fmt.Sprintf("overhead %dms", d.delay)
+}
@@ -0,0 +1,176 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Dependent is implemented by processes that only run once their dependencies succeeded
+type Dependent interface {
+	Dependencies() []Proccess
+}
+
+// DependsOn declares processes that must have succeeded before the function runs,
+// the function is skipped otherwise
+func (f *Function) DependsOn(ps ...Proccess) {
+	f.deps = append(f.deps, ps...)
+}
+
+// Dependencies returns the processes the function depends on
+func (f *Function) Dependencies() []Proccess {
+	return f.deps
+}
+
+// Succeeded returns true if the last run of the function executed without failing or
+// being skipped
+func (f *Function) Succeeded() bool {
+	return f.isExecuted.get() && f.succeeded.get()
+}
+
+// skipDependency marks the function as skipped for a dependency not met
+func (f *Function) skipDependency() {
+	f.isExecuted.set(true)
+	f.succeeded.set(false)
+}
+
+// succeeded reports whether p has been executed, and has succeeded when it tells so
+func succeeded(p Proccess) bool {
+	if s, ok := p.(interface{ Succeeded() bool }); ok {
+		return s.Succeeded()
+	}
+	return p.IsExecuted()
+}
+
+// runProcess runs p against ctx through the middlewares of ctx, or records a skipped
+// row when a dependency of p has not succeeded
+func runProcess(ctx context.Context, w io.Writer, p Proccess) {
+	if overdue(ctx) {
+		return
+	}
+	ctx = enter(ctx, p)
+	if d, ok := p.(Dependent); ok {
+		for _, dep := range d.Dependencies() {
+			if succeeded(dep) {
+				continue
+			}
+			skipProcess(ctx, w, p, "dependency not met: "+dep.String())
+			return
+		}
+	}
+	defer recoverProcess(ctx, w, p, begin(ctx))
+	if mws := middlewareOf(ctx); len(mws) > 0 {
+		through(ctx, w, p, mws)
+		return
+	}
+	p.Run(ctx, w)
+}
+
+// skipProcess records p skipped with note. p is marked executed when it can be, and is
+// not reported unexecuted by the run otherwise.
+func skipProcess(ctx context.Context, w io.Writer, p Proccess, note string) {
+	if s, ok := p.(interface{ skipDependency() }); ok {
+		s.skipDependency()
+	}
+	if r, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+		r.skip(p)
+	}
+	skip(ctx, w, p.String(), Record{Note: note})
+}
+
+// skip marks p as skipped, so it is not reported unexecuted, by the run the records are
+// held from as well
+func (r *recorder) skip(p Proccess) {
+	if r.parent != nil {
+		r.parent.skip(p)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.skipped == nil {
+		r.skipped = make(map[Proccess]bool)
+	}
+	r.skipped[p] = true
+}
+
+// isSkipped returns true if p has been skipped during the run, by a middleware or for
+// a dependency not met
+func (r *recorder) isSkipped(p Proccess) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.skipped[p]
+}
+
+// checkDependencies returns an error wrapping ErrDependencyCycle when the dependencies
+// of ps, and of their children, form a cycle or nest owner in itself, or matching
+// ErrNilProcess when one of them is nil
+func checkDependencies(owner *Simulator, ps []Proccess) error {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[Proccess]int)
+	var path []Proccess
+	var visit func(p Proccess) error
+	visit = func(p Proccess) error {
+		if isNil(p) {
+			return ErrNilProcess
+		}
+		switch state[p] {
+		case visited:
+			return nil
+		case visiting:
+			var names []string
+			for i := len(path) - 1; i >= 0 && path[i] != p; i-- {
+				names = append([]string{path[i].String()}, names...)
+			}
+			names = append(append([]string{p.String()}, names...), p.String())
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(names, " -> "))
+		}
+		state[p] = visiting
+		path = append(path, p)
+		var next []Proccess
+		if d, ok := p.(Dependent); ok {
+			next = append(next, d.Dependencies()...)
+		}
+		if sp, ok := p.(*simulatorProcess); ok {
+			if owner != nil && sp.s == owner {
+				names := make([]string, len(path))
+				for i, q := range path {
+					names[i] = q.String()
+				}
+				return fmt.Errorf("%w: simulator %s nested in itself: %s", ErrDependencyCycle, owner.name, strings.Join(names, " -> "))
+			}
+			// the nested simulator may be registering processes itself
+			sp.s.mu.Lock()
+			next = append(next, sp.s.process...)
+			sp.s.mu.Unlock()
+		} else if c, ok := p.(Composite); ok {
+			next = append(next, c.Children()...)
+		}
+		for _, n := range next {
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[p] = visited
+		return nil
+	}
+	for _, p := range ps {
+		if err := visit(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isNil returns true when p is nil or a nil pointer, e.g. a (*FunctionWithTimeout)(nil)
+func isNil(p Proccess) bool {
+	if p == nil {
+		return true
+	}
+	v := reflect.ValueOf(p)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,79 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// dependent is a process with dependencies that cannot be marked skipped
+type dependent struct {
+	name     string
+	deps     []Proccess
+	executed flag
+}
+
+func (d *dependent) Run(ctx context.Context, w io.Writer) {
+	d.executed.set(true)
+}
+
+func (d *dependent) IsExecuted() bool {
+	return d.executed.get()
+}
+
+func (d *dependent) String() string {
+	return d.name
+}
+
+func (d *dependent) Dependencies() []Proccess {
+	return d.deps
+}
+
+func TestDependentSkippedOnce(t *testing.T) {
+	a := NewFunction("a").WithTimeout(10).WithFailureRate(1)
+	s := NewSimulator("deps", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(a, &dependent{name: "d", deps: []Proccess{a}})
+	res, _ := s.Run()
+	d := res.ByName("d")
+	if len(d) != 1 || !d[0].Skipped || d[0].Note != "dependency not met: a" {
+		t.Errorf("records of d %+v, want a single skipped one", d)
+	}
+	if got := res.Unexecuted(); len(got) != 0 {
+		t.Errorf("unexecuted %v, want none", got)
+	}
+}
+
+func TestCheckDependencies(t *testing.T) {
+	self := NewFunction("self").WithTimeout(10)
+	self.DependsOn(self)
+	a, b := NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(10)
+	a.DependsOn(b)
+	b.DependsOn(a)
+	registered, unregistered := NewFunction("registered").WithTimeout(10), NewFunction("unregistered").WithTimeout(10)
+	registered.DependsOn(unregistered)
+	orphan := NewFunction("orphan").WithTimeout(10)
+	orphan.DependsOn(nil)
+
+	tests := map[string]struct {
+		ps   []Proccess
+		want error
+	}{
+		"self-dependency":      {ps: []Proccess{self}, want: ErrDependencyCycle},
+		"two-node cycle":       {ps: []Proccess{a, b}, want: ErrDependencyCycle},
+		"missing dependency":   {ps: []Proccess{registered}},
+		"nil dependency":       {ps: []Proccess{orphan}, want: ErrNilProcess},
+		"no dependency at all": {ps: []Proccess{unregistered}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := checkDependencies(nil, tt.ps)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if err := checkDependencies(nil, []Proccess{a}); err == nil || err.Error() != "t0simulator: dependency cycle: a -> b -> a" {
+		t.Errorf("error %v, want the cycle a -> b -> a", err)
+	}
+}
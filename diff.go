@@ -0,0 +1,208 @@
+package t0simulator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Diff denotes the differences between two runs, e.g. of a scenario before and after
+// tuning its weights
+type Diff struct {
+	A, B      *Result
+	Processes []ProcessDiff
+}
+
+// ProcessDiff denotes the differences of a single process between two runs. A process
+// recorded in a single run is Added or Removed, the fields of the other run are zero.
+type ProcessDiff struct {
+	Name    string
+	Depth   int
+	Added   bool
+	Removed bool
+	// StatusA, ConsumedA and RemainingA are the fields of the record in the first run,
+	// the ones ending with B of the record in the second run
+	StatusA, StatusB       Status
+	ConsumedA, ConsumedB   time.Duration
+	RemainingA, RemainingB time.Duration
+}
+
+// Consumed returns the change of the time the process consumed, positive when it
+// consumed more in the second run
+func (p ProcessDiff) Consumed() time.Duration {
+	return p.ConsumedB - p.ConsumedA
+}
+
+// Remaining returns the change of the time left after the process, positive when more
+// time was left in the second run
+func (p ProcessDiff) Remaining() time.Duration {
+	return p.RemainingB - p.RemainingA
+}
+
+// Regressed returns true if the process did worse in the second run: it consumed more
+// although it was not stopped by the deadline in the first one, or it no longer succeeds
+func (p ProcessDiff) Regressed() bool {
+	if p.Added || p.Removed {
+		return false
+	}
+	return !timedOut(p.StatusA) && p.Consumed() > 0 || p.StatusA == StatusOK && p.StatusB != StatusOK
+}
+
+// Compare returns the differences between the runs a and b. The records are matched by
+// name, and by position among the records of the same name, e.g. the iterations of a
+// repeated process.
+func Compare(a, b *Result) *Diff {
+	d := &Diff{A: a, B: b}
+	index := make(map[string]int)
+	for _, key := range recordKeys(a.Records) {
+		rec := a.Records[key.i]
+		index[key.key] = len(d.Processes)
+		d.Processes = append(d.Processes, ProcessDiff{Name: rec.Name, Depth: rec.Depth, Removed: true,
+			StatusA: rec.Status(), ConsumedA: rec.Consumed, RemainingA: rec.Remaining})
+	}
+	// the records of b only are inserted after the last record both runs have
+	last := -1
+	for _, key := range recordKeys(b.Records) {
+		rec := b.Records[key.i]
+		j, ok := index[key.key]
+		if !ok {
+			last++
+			d.Processes = append(d.Processes[:last], append([]ProcessDiff{{Name: rec.Name, Depth: rec.Depth, Added: true}}, d.Processes[last:]...)...)
+			for k, i := range index {
+				if i >= last {
+					index[k] = i + 1
+				}
+			}
+			j = last
+		} else {
+			d.Processes[j].Removed = false
+			last = j
+		}
+		p := &d.Processes[j]
+		p.StatusB, p.ConsumedB, p.RemainingB = rec.Status(), rec.Consumed, rec.Remaining
+	}
+	return d
+}
+
+// recordKey identifies the i-th record by its name, depth and rank among the records of
+// the same name
+type recordKey struct {
+	key string
+	i   int
+}
+
+func recordKeys(records []Record) []recordKey {
+	seen := make(map[string]int)
+	keys := make([]recordKey, len(records))
+	for i, rec := range records {
+		name := fmt.Sprintf("%d/%s", rec.Depth, rec.Name)
+		keys[i] = recordKey{key: fmt.Sprintf("%s#%d", name, seen[name]), i: i}
+		seen[name]++
+	}
+	return keys
+}
+
+// Remaining returns the change of the time left at the end of the run, positive when
+// the second run left more time
+func (d *Diff) Remaining() time.Duration {
+	return d.B.Remaining - d.A.Remaining
+}
+
+// Verdict summarizes the differences, e.g. "time left improved by 42 ms; 1 function no
+// longer times out"
+func (d *Diff) Verdict() string {
+	var parts []string
+	switch delta := d.Remaining(); {
+	case delta > 0:
+		parts = append(parts, fmt.Sprintf("time left improved by %s ms", ms(delta)))
+	case delta < 0:
+		parts = append(parts, fmt.Sprintf("time left regressed by %s ms", ms(-delta)))
+	default:
+		parts = append(parts, "time left unchanged")
+	}
+	var fixed, broken, recovered, failing int
+	for _, p := range d.Processes {
+		if p.Added || p.Removed {
+			continue
+		}
+		switch {
+		case timedOut(p.StatusA) && !timedOut(p.StatusB):
+			fixed++
+		case !timedOut(p.StatusA) && timedOut(p.StatusB):
+			broken++
+		}
+		switch {
+		case p.StatusA == StatusFailed && p.StatusB == StatusOK:
+			recovered++
+		case p.StatusA != StatusFailed && p.StatusB == StatusFailed:
+			failing++
+		}
+	}
+	for _, c := range []struct {
+		n              int
+		single, plural string
+	}{
+		{fixed, "no longer times out", "no longer time out"},
+		{broken, "now times out", "now time out"},
+		{recovered, "no longer fails", "no longer fail"},
+		{failing, "now fails", "now fail"},
+	} {
+		switch {
+		case c.n == 1:
+			parts = append(parts, "1 function "+c.single)
+		case c.n > 1:
+			parts = append(parts, fmt.Sprintf("%d functions %s", c.n, c.plural))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// timedOut returns true for the statuses of the processes the deadline stopped
+func timedOut(s Status) bool {
+	return s == StatusInProgress || s == StatusUnexecuted
+}
+
+// WriteTable writes the differences to w, the regressions are tagged, and in red on a terminal
+func (d *Diff) WriteTable(w io.Writer) error {
+	tw := newTabWriter(w, defaultTabConfig)
+	l := layout{color: isTerminal(w)}
+	fmt.Fprint(tw, "=====================\n")
+	fmt.Fprintf(tw, "DIFF:%s -> %s\n", d.A.Name, d.B.Name)
+	l.writeRow(tw, colorDefault, "Name", "Consumed A(ms)", "Consumed B(ms)", "+/-(ms)", "Remaining A(ms)", "Remaining B(ms)", "+/-(ms)", "Status")
+	for _, p := range d.Processes {
+		name := strings.Repeat("  ", p.Depth) + p.Name
+		c := colorDefault
+		if p.Regressed() {
+			c = colorRed
+		}
+		switch {
+		case p.Added:
+			l.writeRow(tw, c, name, "", ms(p.ConsumedB), "", "", ms(p.RemainingB), "", "added, "+string(p.StatusB))
+		case p.Removed:
+			l.writeRow(tw, c, name, ms(p.ConsumedA), "", "", ms(p.RemainingA), "", "", "removed, was "+string(p.StatusA))
+		default:
+			status := string(p.StatusB)
+			if p.StatusA != p.StatusB {
+				status = string(p.StatusA) + " -> " + status
+			}
+			cells := []interface{}{name, ms(p.ConsumedA), ms(p.ConsumedB), signed(p.Consumed()), ms(p.RemainingA), ms(p.RemainingB), signed(p.Remaining()), status}
+			if p.Regressed() {
+				cells = append(cells, "regressed")
+			}
+			l.writeRow(tw, c, cells...)
+		}
+	}
+	fmt.Fprintf(tw, "Time left %s ms -> %s ms (%s ms)\n", ms(d.A.Remaining), ms(d.B.Remaining), signed(d.Remaining()))
+	fmt.Fprintln(tw, d.Verdict())
+	fmt.Fprint(tw, "=====================\n")
+	return tw.Flush()
+}
+
+// signed returns d in ms with its sign, "+" for positive
+func signed(d time.Duration) string {
+	if d > 0 {
+		return "+" + ms(d)
+	}
+	return ms(d)
+}
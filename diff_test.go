@@ -0,0 +1,48 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompare(t *testing.T) {
+	before := NewSimulator("before", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	before.RegisterFunctions(NewFunction("a").WithTimeout(40), NewFunction("b").WithTimeout(80))
+	a, _ := before.Run()
+	after := NewSimulator("after", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	after.RegisterFunctions(NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(80), NewFunction("c").WithTimeout(5))
+	b, err := after.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := Compare(a, b)
+	if len(d.Processes) != 3 {
+		t.Fatalf("%d processes compared, want 3", len(d.Processes))
+	}
+	pa, pb, pc := d.Processes[0], d.Processes[1], d.Processes[2]
+	if pa.Name != "a" || pa.Consumed() != -30*time.Millisecond || pa.Remaining() != 30*time.Millisecond || pa.Regressed() {
+		t.Errorf("a consumed %v and left %v more, regressed %t", pa.Consumed(), pa.Remaining(), pa.Regressed())
+	}
+	// b was interrupted in the first run, consuming more in the second is no regression
+	if pb.Name != "b" || pb.StatusA != StatusInProgress || pb.StatusB != StatusOK || pb.Regressed() {
+		t.Errorf("b %s -> %s, regressed %t", pb.StatusA, pb.StatusB, pb.Regressed())
+	}
+	if pc.Name != "c" || !pc.Added || pc.ConsumedB != 5*time.Millisecond {
+		t.Errorf("c %+v, want it added", pc)
+	}
+	if got, want := d.Verdict(), "time left improved by 5 ms; 1 function no longer times out"; got != want {
+		t.Errorf("verdict %q, want %q", got, want)
+	}
+
+	back := Compare(b, a)
+	if pa := back.Processes[0]; !pa.Regressed() {
+		t.Error("a consuming more in the second run is no regression")
+	}
+	if pc := back.Processes[2]; !pc.Removed || pc.Regressed() {
+		t.Errorf("c %+v, want it removed", pc)
+	}
+	if got, want := back.Verdict(), "time left regressed by 5 ms; 1 function now times out"; got != want {
+		t.Errorf("verdict %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package t0simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Describer is implemented by processes that can describe their declared parameters,
+// e.g. "timeout 20ms"
+type Describer interface {
+	Describe() string
+}
+
+// Composite is implemented by processes made of other processes
+type Composite interface {
+	Children() []Proccess
+}
+
+// WriteDOT writes the registered processes as a Graphviz DOT digraph to w, with one
+// node per process linked in registration order. Composite processes are written
+// as subgraphs, the children of the sequential ones linked in order and those of the
+// others fanning out of and back into the subgraph. The budget of a simulator with a deadline is the time left before it.
+func (s *Simulator) WriteDOT(w io.Writer) error {
+	budget := s.budget()
+	if !s.deadline.IsZero() {
+		budget = budget.Round(time.Millisecond)
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n", strconv.Quote(s.name))
+	fmt.Fprintf(bw, "\tlabel=%s;\n", strconv.Quote(fmt.Sprintf("%s (budget %s)", s.name, budget)))
+	fmt.Fprint(bw, "\trankdir=LR;\n")
+	fmt.Fprint(bw, "\tnode [shape=box];\n")
+	writeDOTChain(bw, "p", s.process, "\t")
+	fmt.Fprint(bw, "}\n")
+	return bw.Flush()
+}
+
+// writeDOTChain writes ps as nodes linked in order and returns the ids of the first
+// and the last node of the chain
+func writeDOTChain(w io.Writer, prefix string, ps []Proccess, indent string) (first, last string) {
+	for i, p := range ps {
+		id := fmt.Sprintf("%s%d", prefix, i)
+		in, out := writeDOTNode(w, id, p, indent)
+		if i == 0 {
+			first = in
+		} else {
+			fmt.Fprintf(w, "%s%s -> %s;\n", indent, last, in)
+		}
+		last = out
+	}
+	return first, last
+}
+
+// writeDOTFan writes ps as nodes linked from an entry point and to an exit point, and
+// returns the ids of both points
+func writeDOTFan(w io.Writer, prefix string, ps []Proccess, indent string) (in, out string) {
+	in, out = prefix+"in", prefix+"out"
+	fmt.Fprintf(w, "%s%s [shape=point];\n", indent, in)
+	fmt.Fprintf(w, "%s%s [shape=point];\n", indent, out)
+	for i, p := range ps {
+		first, last := writeDOTNode(w, fmt.Sprintf("%s%d", prefix, i), p, indent)
+		fmt.Fprintf(w, "%s%s -> %s;\n", indent, in, first)
+		fmt.Fprintf(w, "%s%s -> %s;\n", indent, last, out)
+	}
+	return in, out
+}
+
+// writeDOTNode writes p as a node, or as a subgraph when it is composite, and returns
+// the ids to link incoming and outgoing edges to
+func writeDOTNode(w io.Writer, id string, p Proccess, indent string) (in, out string) {
+	label := p.String()
+	if d, ok := p.(Describer); ok {
+		label += "\n" + d.Describe()
+	}
+	if c, ok := p.(Composite); ok && len(c.Children()) > 0 {
+		fmt.Fprintf(w, "%ssubgraph cluster_%s {\n", indent, id)
+		fmt.Fprintf(w, "%s\tlabel=%s;\n", indent, strconv.Quote(label))
+		// the processes of a nested simulator run in order too
+		_, chained := p.(sequence)
+		if _, ok := p.(*simulatorProcess); ok {
+			chained = true
+		}
+		if chained {
+			in, out = writeDOTChain(w, id+"_", c.Children(), indent+"\t")
+		} else {
+			in, out = writeDOTFan(w, id+"_", c.Children(), indent+"\t")
+		}
+		fmt.Fprintf(w, "%s}\n", indent)
+		return in, out
+	}
+	fmt.Fprintf(w, "%s%s [label=%s];\n", indent, id, strconv.Quote(label))
+	return id, id
+}
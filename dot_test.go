@@ -0,0 +1,49 @@
+package t0simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteDOTBudget(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	relative := NewSimulator("relative", 250)
+	deadline, err := NewSimulatorWithDeadline("deadline", start.Add(2*time.Second), WithClock(NewFakeClock(start)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s, label := range map[*Simulator]string{
+		relative: `label="relative (budget 250ms)";`,
+		deadline: `label="deadline (budget 2s)";`,
+	} {
+		var out bytes.Buffer
+		if err := s.WriteDOT(&out); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), label) {
+			t.Errorf("graph misses %s\n%s", label, out.String())
+		}
+	}
+}
+
+func TestWriteDOTParallelMembers(t *testing.T) {
+	s := NewSimulator("dot", 250)
+	s.RegisterFunctions(
+		NewParallelGroup("g", NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(10)),
+		NewSequentialGroup("s", NewFunction("c").WithTimeout(10), NewFunction("d").WithTimeout(10)),
+	)
+	var out bytes.Buffer
+	if err := s.WriteDOT(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, edge := range []string{"p0_in -> p0_0;", "p0_in -> p0_1;", "p0_0 -> p0_out;", "p0_1 -> p0_out;", "p0_out -> p1_0;", "p1_0 -> p1_1;"} {
+		if !strings.Contains(out.String(), edge) {
+			t.Errorf("graph misses %s\n%s", edge, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "p0_0 -> p0_1;") {
+		t.Errorf("members of the parallel group are chained\n%s", out.String())
+	}
+}
@@ -0,0 +1,120 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrBudgetExceeded is matched by the error returned from Run when the deadline is reached
+	ErrBudgetExceeded = errors.New("t0simulator: budget exceeded")
+	// ErrNoResult is returned when saving the report of a simulator that has not run yet
+	ErrNoResult = errors.New("t0simulator: simulator has not run yet")
+	// ErrDependencyCycle is matched by the error returned from RegisterFunctions when
+	// the dependencies of the processes form a cycle, or nest the simulator in itself
+	ErrDependencyCycle = errors.New("t0simulator: dependency cycle")
+	// ErrNilProcess is matched by the error returned from RegisterFunctions when a
+	// process is nil or a nil pointer
+	ErrNilProcess = errors.New("t0simulator: nil process")
+	// ErrSLOViolated is matched by the error returned from Run when an assertion failed
+	ErrSLOViolated = errors.New("t0simulator: SLO violated")
+	// ErrCancelled is matched by the error returned from RunContext when the parent
+	// context is cancelled before the deadline
+	ErrCancelled = errors.New("t0simulator: cancelled by caller")
+	// ErrDeadlinePassed is returned when the absolute deadline of a simulator has
+	// passed before it runs
+	ErrDeadlinePassed = errors.New("t0simulator: deadline passed")
+	// ErrInvalidConfig is matched by the error returned from Validate and Run when the
+	// configuration of a simulator or of its processes is invalid
+	ErrInvalidConfig = errors.New("t0simulator: invalid configuration")
+	// ErrPanicked is matched by the error returned from Run when a process panicked
+	ErrPanicked = errors.New("t0simulator: process panicked")
+	// ErrWallTimeGuard is matched by the error returned from Run when the run was
+	// abandoned by the wall-time guard, see WithMaxWallTime
+	ErrWallTimeGuard = errors.New("t0simulator: wall-time guard")
+	// ErrNoFixedTimeout is matched by the error returned from OverrideTimeout when the
+	// process has no fixed timeout to override
+	ErrNoFixedTimeout = errors.New("t0simulator: no fixed timeout")
+)
+
+// BudgetExceededError denotes a run that reached its deadline, it wraps ErrBudgetExceeded
+type BudgetExceededError struct {
+	Unexecuted []string
+}
+
+func (e *BudgetExceededError) Error() string {
+	if len(e.Unexecuted) == 0 {
+		return ErrBudgetExceeded.Error()
+	}
+	return fmt.Sprintf("%s with unexecuted function: %s", ErrBudgetExceeded, strings.Join(e.Unexecuted, ", "))
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// CancelledError denotes a run whose parent context was cancelled before the deadline,
+// it wraps both ErrCancelled and context.Canceled
+type CancelledError struct {
+	Unexecuted []string
+}
+
+func (e *CancelledError) Error() string {
+	if len(e.Unexecuted) == 0 {
+		return ErrCancelled.Error()
+	}
+	return fmt.Sprintf("%s with unexecuted function: %s", ErrCancelled, strings.Join(e.Unexecuted, ", "))
+}
+
+func (e *CancelledError) Unwrap() []error {
+	return []error{ErrCancelled, context.Canceled}
+}
+
+// PanicError denotes a process whose Run panicked, it wraps ErrPanicked
+type PanicError struct {
+	Process string
+	// Value is the value the process panicked with, and Stack the stack of the panic
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrPanicked, e.Process, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	return ErrPanicked
+}
+
+// WallTimeGuardError denotes a run abandoned by the wall-time guard, it wraps ErrWallTimeGuard
+type WallTimeGuardError struct {
+	After time.Duration
+	Stuck []string
+}
+
+func (e *WallTimeGuardError) Error() string {
+	if len(e.Stuck) == 0 {
+		return fmt.Sprintf("%s after %s", ErrWallTimeGuard, e.After)
+	}
+	return fmt.Sprintf("%s after %s, stuck in: %s", ErrWallTimeGuard, e.After, strings.Join(e.Stuck, ", "))
+}
+
+func (e *WallTimeGuardError) Unwrap() error {
+	return ErrWallTimeGuard
+}
+
+// SLOViolationError denotes a run in which assertions failed, it wraps ErrSLOViolated
+type SLOViolationError struct {
+	Violations []string
+}
+
+func (e *SLOViolationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSLOViolated, strings.Join(e.Violations, ", "))
+}
+
+func (e *SLOViolationError) Unwrap() error {
+	return ErrSLOViolated
+}
@@ -0,0 +1,365 @@
+package t0simulator
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EstimateResult denotes the outcome of a run computed from the declared latencies of
+// the processes, without running them
+type EstimateResult struct {
+	Name   string
+	Budget time.Duration
+	// Reserved is the part of the budget kept back from the processes like in a run,
+	// Budget is the working budget they are estimated against, without it
+	Reserved time.Duration
+	// Basis is the statistic random latencies are estimated with, "mean" or a percentile such as "p95"
+	Basis string
+	// Steps holds the estimate of every process, in the order of the report rows
+	Steps []EstimateStep
+	// Fits is true when the registered processes are expected to finish within the budget
+	Fits bool
+	// RunsOutAt is the name of the process the budget is expected to run out in, empty when it fits
+	RunsOutAt string
+	// Remaining is the time expected to be left once the processes are done, negative when it does not fit
+	Remaining time.Duration
+	// Unknown holds the names of the processes whose duration cannot be estimated, they
+	// are counted as taking no time
+	Unknown []string
+}
+
+// EstimateStep denotes the estimate of a single process
+type EstimateStep struct {
+	Name  string
+	Depth int
+	// Estimated is the time the process is expected to take, and Remaining the budget
+	// expected to be left once it is done
+	Estimated time.Duration
+	Remaining time.Duration
+	// Basis tells where Estimated comes from: "declared", "allotted", "mean", a
+	// percentile such as "p95", "skipped", or "unknown"
+	Basis string
+}
+
+// Estimate returns the outcome of a run computed without running the processes: the
+// declared timeouts are summed up, dynamic context functions are allotted their share
+// with the same computation as a run, and random latencies are estimated with their
+// mean. See EstimateAt to estimate them with a percentile instead.
+func (s *Simulator) Estimate() EstimateResult {
+	return s.EstimateAt(0)
+}
+
+// EstimateAt is like Estimate with random latencies estimated with their percentile-th
+// percentile, in (0,100). A percentile out of that range estimates them with their mean.
+func (s *Simulator) EstimateAt(percentile float64) EstimateResult {
+	s.running.Lock()
+	defer s.running.Unlock()
+	e := &estimation{percentile: percentile / 100, basis: "mean"}
+	if e.percentile <= 0 || e.percentile >= 1 {
+		e.percentile = 0
+	} else {
+		e.basis = fmt.Sprintf("p%v", percentile)
+	}
+
+	// the processes are estimated against the working budget of a run
+	budget := max(s.budget(), 0)
+	reserved := min(s.reserved, budget)
+	budget -= reserved
+	e.budget = budget
+	e.priorityThreshold = s.priorityThreshold
+	e.allocation = s.allocation
+	e.plan = newPlan(s.process, budget)
+	res := EstimateResult{Name: s.name, Budget: budget, Reserved: reserved, Basis: e.basis, Remaining: budget}
+	var makespan time.Duration
+	for i, p := range s.process {
+		remaining := res.Remaining
+		if s.execution == Parallel {
+			remaining = budget
+		} else {
+			e.pending = s.process[i+1:]
+		}
+		left := e.process(p, 0, remaining)
+		if s.execution == Parallel {
+			makespan = max(makespan, budget-left)
+		} else {
+			res.Remaining = left
+		}
+	}
+	if s.execution == Parallel {
+		res.Remaining = budget - makespan
+	}
+
+	res.Steps, res.Unknown = e.steps, e.unknown
+	res.Fits = res.Remaining >= 0
+	// the steps of children come first, so the first one running out is the innermost
+	for _, step := range res.Steps {
+		if !res.Fits && step.Remaining < 0 {
+			res.RunsOutAt = step.Name
+			break
+		}
+	}
+	return res
+}
+
+// WriteTable writes the estimate as a table to w
+func (e EstimateResult) WriteTable(w io.Writer) error {
+	tw := newTabWriter(w, defaultTabConfig)
+	fmt.Fprint(tw, "=====================\n")
+	fmt.Fprintf(tw, "ESTIMATE:%s (random latencies at %s)\n", e.Name, e.Basis)
+	fmt.Fprint(tw, "Name\tEstimated(ms)\tRemaining(ms)\tBasis\t\n")
+	if e.Reserved > 0 {
+		fmt.Fprintf(tw, "Init\t%s\t%s\t\t%s ms reserved of %s ms\n", ms(e.Budget), ms(e.Budget), ms(e.Reserved), ms(e.Budget+e.Reserved))
+	} else {
+		fmt.Fprintf(tw, "Init\t%s\t%s\t\t\n", ms(e.Budget), ms(e.Budget))
+	}
+	for _, step := range e.Steps {
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t\n", strings.Repeat("  ", step.Depth), step.Name, ms(step.Estimated), ms(step.Remaining), step.Basis)
+	}
+	if e.Fits {
+		fmt.Fprintf(tw, "Expected to fit with time left %s ms\n", ms(e.Remaining))
+	} else {
+		fmt.Fprintf(tw, "Expected to run out of budget in %s, %s ms over\n", e.RunsOutAt, ms(-e.Remaining))
+	}
+	if len(e.Unknown) > 0 {
+		fmt.Fprintf(tw, "Not estimated: %s\n", strings.Join(e.Unknown, ", "))
+	}
+	fmt.Fprint(tw, "=====================\n")
+	return tw.Flush()
+}
+
+// estimation collects the steps of an estimate
+type estimation struct {
+	// percentile is the quantile random latencies are estimated with, zero for the mean
+	percentile float64
+	basis      string
+	// budget is the original budget of the simulator being estimated
+	budget  time.Duration
+	steps   []EstimateStep
+	unknown []string
+	// priorityThreshold is the threshold of the simulator being estimated, see WithPriorityThreshold,
+	// and pending the processes registered after the one being estimated
+	priorityThreshold time.Duration
+	pending           []Proccess
+	allocation        AllocationStrategy
+	plan              plan
+}
+
+// estimator is implemented by processes whose duration can be estimated, estimate
+// returns the time the process is expected to take with remaining time left and the
+// basis of the estimate. Composite processes estimate their children with e.
+type estimator interface {
+	estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string)
+}
+
+// process estimates p, started with remaining time left, records its step after the
+// ones of its children and returns the time expected to be left once it is done
+func (e *estimation) process(p Proccess, depth int, remaining time.Duration) time.Duration {
+	estimated, basis := time.Duration(0), "unknown"
+	if est, ok := p.(estimator); ok {
+		estimated, basis = est.estimate(e, depth, remaining)
+		// shares of a negative remaining budget are negative, the process takes no time
+		estimated = max(estimated, 0)
+	} else {
+		e.unknown = append(e.unknown, p.String())
+	}
+	left := remaining - estimated
+	e.steps = append(e.steps, EstimateStep{Name: p.String(), Depth: depth, Estimated: estimated, Remaining: left, Basis: basis})
+	return left
+}
+
+// latency returns the estimate of a random latency given by its mean and its quantile function
+func (e *estimation) latency(mean time.Duration, quantile func(q float64) time.Duration) (time.Duration, string) {
+	if e.percentile == 0 {
+		return mean, e.basis
+	}
+	return max(quantile(e.percentile), 0), e.basis
+}
+
+// milliseconds returns ms as a duration
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+func (f *FunctionWithTimeout) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	if f.skipIfOverBudget && remaining < f.timeout {
+		return 0, "skipped"
+	}
+	timeout := f.timeout
+	if f.coldStart > 0 && !f.warm.get() {
+		timeout += time.Duration(f.coldStart) * time.Millisecond
+	}
+	return timeout, "declared"
+}
+
+func (f *FunctionWithDynamiContext) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	min := time.Duration(f.minTimeout) * time.Millisecond
+	max := time.Duration(f.maxTimeout) * time.Millisecond
+	req := AllocationRequest{
+		Name:       f.name,
+		Remaining:  remaining,
+		Weight:     f.weight,
+		Priority:   f.priority,
+		Threshold:  e.priorityThreshold,
+		MinTimeout: time.Duration(f.floor) * time.Millisecond,
+		Pending:    e.pending,
+		Planned:    e.plan.slice(f.weight),
+	}
+	a := allocate(e.allocation, req, min, max)
+	if a.insufficient {
+		return 0, "skipped, insufficient budget"
+	}
+	if a.skipped {
+		return 0, "demoted, skipped"
+	}
+	return a.allotted, "allotted"
+}
+
+func (f *FunctionWithBudgetShare) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	return min(time.Duration(float64(e.budget)*f.share), remaining), "allotted"
+}
+
+func (f *FunctionWithCache) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	hit, miss := time.Duration(f.hitLatency)*time.Millisecond, time.Duration(f.missLatency)*time.Millisecond
+	mean := f.hitRate*float64(f.hitLatency) + (1-f.hitRate)*float64(f.missLatency)
+	return e.latency(milliseconds(mean), func(q float64) time.Duration {
+		if q < f.hitRate {
+			return min(hit, miss)
+		}
+		return max(hit, miss)
+	})
+}
+
+func (d *Delay) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	return time.Duration(d.delay) * time.Millisecond, "declared"
+}
+
+func (f *FunctionWithRandomLatency) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	return e.latency(milliseconds(float64(f.min+f.max)/2), func(q float64) time.Duration {
+		return milliseconds(float64(f.min) + q*float64(f.max-f.min))
+	})
+}
+
+func (f *FunctionWithNormalLatency) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	return e.latency(milliseconds(math.Max(f.mean, 0)), func(q float64) time.Duration {
+		return milliseconds(f.mean + f.stddev*math.Sqrt2*math.Erfinv(2*q-1))
+	})
+}
+
+func (f *FunctionWithLatencyProfile) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	p50, p95, p99 := float64(f.p50), float64(f.p95), float64(f.p99)
+	// the mean of every bucket weighted by its probability, the tail is exponential
+	mean := p50/2*0.50 + (p50+p95)/2*0.45 + (p95+p99)/2*0.04 + (p99+p99-p95)*0.01
+	return e.latency(milliseconds(mean), func(q float64) time.Duration {
+		interpolate := func(fromU, toU, from, to float64) time.Duration {
+			return milliseconds(from + (q-fromU)/(toU-fromU)*(to-from))
+		}
+		switch {
+		case q < 0.50:
+			return interpolate(0, 0.50, 0, p50)
+		case q < 0.95:
+			return interpolate(0.50, 0.95, p50, p95)
+		case q < 0.99:
+			return interpolate(0.95, 0.99, p95, p99)
+		}
+		return milliseconds(p99 - math.Log((1-q)/0.01)*(p99-p95))
+	})
+}
+
+func (f *FunctionWithSampledLatency) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	switch s := f.sampler.(type) {
+	case *HistogramSampler:
+		return s.estimate(e)
+	case fixedLatency:
+		return time.Duration(s), "declared"
+	}
+	e.unknown = append(e.unknown, f.name)
+	return 0, "unknown"
+}
+
+// estimate returns the estimate of a latency drawn from the histogram
+func (h *HistogramSampler) estimate(e *estimation) (time.Duration, string) {
+	total := float64(h.cumulative[len(h.cumulative)-1])
+	var mean float64
+	for i, bound := range h.bounds {
+		from, count := 0, h.cumulative[i]
+		if i > 0 {
+			from, count = h.bounds[i-1], h.cumulative[i]-h.cumulative[i-1]
+		}
+		mean += float64(from+bound) / 2 * float64(count) / total
+	}
+	return e.latency(milliseconds(mean), func(q float64) time.Duration {
+		u := q * total
+		i := sort.Search(len(h.cumulative), func(i int) bool {
+			return float64(h.cumulative[i]) > u
+		})
+		from, count := 0, h.cumulative[i]
+		if i > 0 {
+			from, count = h.bounds[i-1], h.cumulative[i]-h.cumulative[i-1]
+			u -= float64(h.cumulative[i-1])
+		}
+		return milliseconds(float64(from) + u/float64(count)*float64(h.bounds[i]-from))
+	})
+}
+
+func (g *SequentialGroup) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	left := remaining
+	pending := e.pending
+	for i, c := range g.children {
+		e.pending = append(slices.Clone(g.children[i+1:]), pending...)
+		left = e.process(c, depth+1, left)
+	}
+	e.pending = pending
+	return remaining - left, "sum"
+}
+
+func (g *ParallelGroup) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	var longest time.Duration
+	for _, m := range g.members {
+		longest = max(longest, remaining-e.process(m, depth+1, remaining))
+	}
+	return longest, "slowest"
+}
+
+func (g *BulkheadGroup) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	// the children start in order as soon as one of the limit slots is free
+	slots := make([]time.Duration, g.limit)
+	var makespan time.Duration
+	for _, c := range g.children {
+		sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+		start := slots[0]
+		slots[0] = remaining - e.process(c, depth+1, remaining-start)
+		makespan = max(makespan, slots[0])
+	}
+	return makespan, "scheduled"
+}
+
+func (r *Repeat) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	// like a run, an unbounded repeat stops when the time left is shorter than the last iteration
+	left := remaining
+	var last time.Duration
+	for i := 0; r.n == Unbounded || i < r.n; i++ {
+		if i > 0 && (left < last || r.n == Unbounded && last <= 0) {
+			break
+		}
+		last = left - e.process(r.p, depth+1, left)
+		left -= last
+	}
+	return remaining - left, "repeated"
+}
+
+func (p *simulatorProcess) estimate(e *estimation, depth int, remaining time.Duration) (time.Duration, string) {
+	budget := min(p.s.budget(), remaining)
+	outer := e.budget
+	e.budget = p.s.budget()
+	left := budget
+	for _, c := range p.s.process {
+		left = e.process(c, depth+1, left)
+	}
+	e.budget = outer
+	return min(budget-left, budget), "sum"
+}
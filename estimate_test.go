@@ -0,0 +1,45 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateMatchesRun(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"reserved":  {WithReservedBudget(40)},
+		"threshold": {WithPriorityThreshold(50)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewSimulator(name, 100, append(opts, WithVirtualClock(), WithVerbosity(Quiet))...)
+			s.RegisterFunctions(
+				NewFunction("a").WithTimeout(50),
+				NewFunction("b").WithDynamicContext(0.5, true),
+				NewFunction("c").WithTimeout(10),
+			)
+			est := s.Estimate()
+			res, _ := s.Run()
+			if est.Fits == res.TimedOut {
+				t.Errorf("estimate fits %v, run timed out %v", est.Fits, res.TimedOut)
+			}
+			if est.Budget != res.Budget || est.Reserved != res.Reserved {
+				t.Errorf("estimated budget %v reserved %v, run %v reserved %v", est.Budget, est.Reserved, res.Budget, res.Reserved)
+			}
+			for _, step := range est.Steps {
+				for _, rec := range res.ByName(step.Name) {
+					if rec.Executed && rec.Timeout != step.Estimated {
+						t.Errorf("%s: estimated %v, ran %v", step.Name, step.Estimated, rec.Timeout)
+					}
+				}
+			}
+			if est.Fits && est.Remaining != res.Remaining {
+				t.Errorf("estimated %v left, run %v", est.Remaining, res.Remaining)
+			}
+		})
+	}
+	s := NewSimulator("over", 100, WithReservedBudget(40), WithVerbosity(Quiet))
+	s.RegisterFunctions(NewFunction("a").WithTimeout(70))
+	if est := s.Estimate(); est.Fits || est.Remaining != -10*time.Millisecond {
+		t.Errorf("70ms estimated to fit %v with %v left in a 60ms working budget", est.Fits, est.Remaining)
+	}
+}
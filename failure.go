@@ -0,0 +1,42 @@
+package t0simulator
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// failure denotes the probability of a simulated function to fail after consuming its time
+type failure struct {
+	rate float64
+}
+
+// fail draws from r whether a run fails
+func (f failure) fail(r *random) bool {
+	return f.rate > 0 && r.float64() < f.rate
+}
+
+// setRate sets the failure rate of the function named name, it panics when p is not in [0,1]
+func (f *failure) setRate(name string, p float64) {
+	if p < 0 || p > 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: failure rate %v out of range [0,1]", name, p))
+	}
+	f.rate = p
+}
+
+// WithFailureRate makes the function fail with probability p after consuming its time,
+// it panics when p is not in [0,1]
+func (f *FunctionWithDynamiContext) WithFailureRate(p float64) *FunctionWithDynamiContext {
+	f.failure.setRate(f.name, p)
+	return f
+}
+
+// WithSource sets the source failures are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithDynamiContext) WithSource(src rand.Source) *FunctionWithDynamiContext {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithDynamiContext) WithSeed(seed int64) *FunctionWithDynamiContext {
+	return f.WithSource(rand.NewSource(seed))
+}
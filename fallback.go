@@ -0,0 +1,91 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Fallback denotes a process running a primary process under its own budget and
+// falling back to a secondary process when that budget is exceeded
+type Fallback struct {
+	primary, secondary Proccess
+	primaryBudget      int
+	isExecuted         flag
+}
+
+// NewFallback returns a process running primary under a sub-context of primaryBudget ms,
+// secondary is run with the remaining budget when the sub-deadline fires first
+func NewFallback(primary, secondary Proccess, primaryBudget int) *Fallback {
+	return &Fallback{
+		primary:       primary,
+		secondary:     secondary,
+		primaryBudget: primaryBudget,
+	}
+}
+
+// Run runs the primary process, then the secondary one if the primary budget is exceeded.
+// The rows of an abandoned primary are dropped. Nothing is recorded when ctx expires first.
+func (f *Fallback) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	budget := time.Duration(f.primaryBudget) * time.Millisecond
+	primaryCtx, cancel := withTimeout(ctx, budget)
+	defer cancel()
+	deadline, _ := primaryCtx.Deadline()
+	heldCtx, held := hold(nested(primaryCtx))
+
+	done := make(chan struct{})
+	spawn(ctx, func() {
+		runProcess(heldCtx, held.writer(), f.primary)
+		close(done)
+	})
+
+	rec := Record{Timeout: budget, deadline: deadline}
+	await(ctx, func() bool { return closed(done) || primaryCtx.Err() != nil }, func() {
+		select {
+		case <-done:
+		case <-primaryCtx.Done():
+		}
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	if primaryCtx.Err() == nil {
+		held.release(w)
+		rec.Note = "primary"
+	} else if errors.Is(primaryCtx.Err(), context.DeadlineExceeded) {
+		runProcess(nested(ctx), w, f.secondary)
+		if ctx.Err() != nil {
+			return
+		}
+		rec.Note = "fallback"
+	}
+	f.isExecuted.set(true)
+	sp.end(ctx, w, f.String(), rec)
+}
+
+// IsExecuted returns true if either path has completed
+func (f *Fallback) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (f *Fallback) ResetRun() {
+	f.isExecuted.set(false)
+}
+
+func (f *Fallback) String() string {
+	return f.primary.String() + " or " + f.secondary.String()
+}
+
+// Describe returns the primary budget of the fallback
+func (f *Fallback) Describe() string {
+	return fmt.Sprintf("primary budget %dms", f.primaryBudget)
+}
+
+// Children returns the primary and the secondary processes
+func (f *Fallback) Children() []Proccess {
+	return []Proccess{f.primary, f.secondary}
+}
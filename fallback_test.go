@@ -0,0 +1,47 @@
+package t0simulator
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFallbackAfterPrimaryBudget(t *testing.T) {
+	s := NewSimulator("fallback", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(NewFallback(NewFunction("a").WithTimeout(80), NewFunction("b").WithTimeout(20), 30))
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := res.ByName("a"); len(a) != 0 {
+		t.Errorf("records of the abandoned primary %+v, want none", a)
+	}
+	if b := res.ByName("b"); len(b) != 1 || !b[0].Executed {
+		t.Errorf("records of b %+v, want a single executed one", b)
+	}
+	fb := res.ByName("a or b")
+	if len(fb) != 1 || !fb[0].Executed || fb[0].Note != "fallback" {
+		t.Fatalf("records of the fallback %+v, want a single executed fallback", fb)
+	}
+	if fb[0].Consumed != 50*time.Millisecond {
+		t.Errorf("fallback consumed %v, want 50ms", fb[0].Consumed)
+	}
+}
+
+func TestFallbackInterruptedByRunDeadline(t *testing.T) {
+	s := NewSimulator("fallback", 50, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(NewFallback(NewFunction("a").WithTimeout(80), NewFunction("b").WithTimeout(20), 60))
+	res, _ := s.Run()
+	if a := res.ByName("a"); len(a) != 1 || !a[0].InProgress {
+		t.Errorf("records of a %+v, want a single one in progress", a)
+	}
+	if b := res.ByName("b"); len(b) != 1 || b[0].State() != StateNotStarted {
+		t.Errorf("records of b %+v, want a single never started one", b)
+	}
+	if !slices.Contains(res.Interrupted(), "a or b") {
+		t.Errorf("interrupted %v, want the fallback", res.Interrupted())
+	}
+	if sum := res.Summary; sum.Executed != 0 || sum.Registered != 1 {
+		t.Errorf("executed %d of %d", sum.Executed, sum.Registered)
+	}
+}
@@ -0,0 +1,344 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ParallelGroup denotes a process running its members concurrently against the same context
+type ParallelGroup struct {
+	name       string
+	members    []Proccess
+	isExecuted flag
+}
+
+// NewParallelGroup returns a process running members in separate goroutines
+func NewParallelGroup(name string, members ...Proccess) *ParallelGroup {
+	return &ParallelGroup{
+		name:    name,
+		members: members,
+	}
+}
+
+// Run runs the members and waits until all of them are done, they stop when ctx expires.
+// The row of the group reports the duration of its slowest member, the critical path.
+func (g *ParallelGroup) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	sw := &syncWriter{w: w}
+	child := nested(ctx)
+	durations := make([]time.Duration, len(g.members))
+
+	var wg waitGroup
+	for i, m := range g.members {
+		wg.spawn(ctx, func() {
+			started := now(ctx)
+			runProcess(child, sw, m)
+			durations[i] = since(ctx, started)
+		})
+	}
+
+	// the members exit once ctx expires, they are waited for so none outlives the run
+	wg.wait(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	rec := Record{}
+	for i, d := range durations {
+		if d >= rec.Timeout {
+			rec.Timeout = d
+			rec.Note = "critical path " + g.members[i].String()
+		}
+	}
+	g.isExecuted.set(true)
+	sp.end(ctx, sw, g.name, rec)
+}
+
+// IsExecuted returns true if all members have finished
+func (g *ParallelGroup) IsExecuted() bool {
+	return g.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (g *ParallelGroup) ResetRun() {
+	g.isExecuted.set(false)
+}
+
+func (g *ParallelGroup) String() string {
+	return g.name
+}
+
+// Describe returns the number of members of the group
+func (g *ParallelGroup) Describe() string {
+	return fmt.Sprintf("parallel, %d members", len(g.members))
+}
+
+// Children returns the members of the group
+func (g *ParallelGroup) Children() []Proccess {
+	return g.members
+}
+
+// BulkheadGroup denotes a process running its children concurrently, with a bounded
+// number of them in flight at once
+type BulkheadGroup struct {
+	name       string
+	limit      int
+	children   []Proccess
+	isExecuted flag
+}
+
+// NewBulkhead returns a process running children concurrently, at most limit at once,
+// the others queue until one of them is done. It panics when limit is lower than 1.
+func NewBulkhead(name string, limit int, children ...Proccess) *BulkheadGroup {
+	if limit < 1 {
+		panic(fmt.Sprintf("t0simulator: bulkhead %q: limit %d lower than 1", name, limit))
+	}
+	return &BulkheadGroup{
+		name:     name,
+		limit:    limit,
+		children: children,
+	}
+}
+
+// Run runs the children and waits until all of them are done, they stop when ctx
+// expires. The time a child queued is reported in its Wait column, and the row of the
+// group reports the makespan and the maximum concurrency observed.
+func (g *BulkheadGroup) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	sw := &syncWriter{w: w}
+	child := nested(ctx)
+	slots := newSemaphore(g.limit)
+	var inFlight, maxInFlight atomic.Int64
+
+	var wg waitGroup
+	for _, c := range g.children {
+		wg.spawn(ctx, func() {
+			queued := now(ctx)
+			if !slots.acquire(ctx) {
+				return
+			}
+			defer slots.release()
+			n := inFlight.Add(1)
+			for m := maxInFlight.Load(); n > m && !maxInFlight.CompareAndSwap(m, n); m = maxInFlight.Load() {
+			}
+			runProcess(withWait(child, since(ctx, queued)), sw, c)
+			inFlight.Add(-1)
+		})
+	}
+
+	wg.wait(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if overdue(ctx) && !allExecuted(g.children) {
+		return
+	}
+
+	g.isExecuted.set(true)
+	sp.end(ctx, sw, g.name, Record{
+		Timeout: since(ctx, sp.startedAt),
+		Note:    fmt.Sprintf("max concurrency %d/%d", maxInFlight.Load(), g.limit),
+	})
+}
+
+// IsExecuted returns true if all children have finished
+func (g *BulkheadGroup) IsExecuted() bool {
+	return g.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (g *BulkheadGroup) ResetRun() {
+	g.isExecuted.set(false)
+}
+
+func (g *BulkheadGroup) String() string {
+	return g.name
+}
+
+// Describe returns the concurrency limit of the group
+func (g *BulkheadGroup) Describe() string {
+	return fmt.Sprintf("bulkhead, %d of %d children at once", g.limit, len(g.children))
+}
+
+// Children returns the children of the group
+func (g *BulkheadGroup) Children() []Proccess {
+	return g.children
+}
+
+// SequentialGroup denotes a process running its children one after another
+type SequentialGroup struct {
+	name     string
+	children []Proccess
+}
+
+// NewSequentialGroup returns a process running children in order
+func NewSequentialGroup(name string, children ...Proccess) *SequentialGroup {
+	return &SequentialGroup{
+		name:     name,
+		children: children,
+	}
+}
+
+// Run runs the children against ctx, it stops when ctx expires before the next child.
+// The row of the group reports the time taken by all its children.
+func (g *SequentialGroup) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	child := nested(ctx)
+	for _, c := range g.children {
+		if stopped(ctx) {
+			return
+		}
+		runProcess(child, w, c)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if overdue(ctx) && !allExecuted(g.children) {
+		return
+	}
+	rec := Record{Timeout: since(ctx, sp.startedAt), Note: fmt.Sprintf("%d children", len(g.children))}
+	sp.end(ctx, w, g.String(), rec)
+}
+
+// IsExecuted returns true if all children have been executed
+func (g *SequentialGroup) IsExecuted() bool {
+	for _, c := range g.children {
+		if !c.IsExecuted() {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the name of the group followed by its children
+func (g *SequentialGroup) String() string {
+	names := make([]string, len(g.children))
+	for i, c := range g.children {
+		names[i] = c.String()
+	}
+	return g.name + "(" + strings.Join(names, ", ") + ")"
+}
+
+// Describe returns the number of children of the group
+func (g *SequentialGroup) Describe() string {
+	return fmt.Sprintf("sequential, %d children", len(g.children))
+}
+
+// Children returns the children of the group
+func (g *SequentialGroup) Children() []Proccess {
+	return g.children
+}
+
+func (g *SequentialGroup) sequential() {}
+
+// simulatorProcess denotes a simulator registered as a process of another simulator
+type simulatorProcess struct {
+	s          *Simulator
+	isExecuted flag
+}
+
+// AsProcess returns the simulator as a process, so it can be registered in another
+// simulator. Its processes run against the remaining budget of the outer simulator,
+// capped by its own budget minus its reservation, with its execution, deadline policy,
+// priority threshold and allocation strategy, and through its middlewares after the
+// ones of the outer simulator. Their rows are printed indented in the report of the
+// outer simulator. Its output, format and hooks are ignored.
+func (s *Simulator) AsProcess() Proccess {
+	return &simulatorProcess{s: s}
+}
+
+// Run runs the processes of the simulator, it stops when the outer context or the
+// budget of the simulator expires. The row of the simulator reports the budget it was
+// allotted, and is failed when that budget was exceeded. It waits for the current
+// run of the simulator, if any.
+func (p *simulatorProcess) Run(ctx context.Context, w io.Writer) {
+	p.s.running.Lock()
+	defer p.s.running.Unlock()
+	c := &p.s.config
+	sp := begin(ctx)
+	budget := max(min(c.budget(), getRemaining(ctx)), 0)
+	budget -= min(c.reserved, budget)
+	sub, cancel := c.deadlinePolicy.deadline(ctx, budget)
+	defer cancel()
+	deadline, _ := sub.Deadline()
+	allotted := getRemaining(sub)
+	child := c.scope(nested(sub), allotted, newPlan(c.process, allotted))
+	child = withSequence(child, c.execution == Sequential)
+	sw := &syncWriter{w: w}
+	c.execute(child, func(q Proccess) bool {
+		if stopped(sub) {
+			return false
+		}
+		runProcess(child, sw, q)
+		return true
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	rec := Record{Timeout: allotted, deadline: deadline}
+	if stopped(sub) && !allExecuted(c.process) {
+		done := 0
+		for _, q := range c.process {
+			if q.IsExecuted() {
+				done++
+			}
+		}
+		rec.Failed = true
+		rec.Note = fmt.Sprintf("time out reached, %d/%d done", done, len(c.process))
+	}
+	p.isExecuted.set(true)
+	sp.end(ctx, sw, p.s.name, rec)
+}
+
+// IsExecuted returns true if the simulator has finished its run
+func (p *simulatorProcess) IsExecuted() bool {
+	return p.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (p *simulatorProcess) ResetRun() {
+	p.isExecuted.set(false)
+}
+
+func (p *simulatorProcess) String() string {
+	return p.s.name
+}
+
+// Describe returns the budget of the simulator
+func (p *simulatorProcess) Describe() string {
+	return fmt.Sprintf("simulator, budget %s", p.s.budget())
+}
+
+// Children returns the processes of the simulator
+func (p *simulatorProcess) Children() []Proccess {
+	return p.s.process
+}
+
+// syncWriter serializes the writes of concurrent processes, rows are written in a
+// single call so they do not interleave. Writes are dropped once it is closed.
+type syncWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closed bool
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return len(p), nil
+	}
+	return s.w.Write(p)
+}
+
+// close waits for the write in progress, if any, and drops the following ones
+func (s *syncWriter) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
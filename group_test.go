@@ -0,0 +1,78 @@
+package t0simulator
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSequentialGroupLastChildPastDeadline(t *testing.T) {
+	s := NewSimulator("group", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(NewSequentialGroup("g", NewFunction("a").WithTimeout(40), NewFunction("b").WithTimeout(80)))
+	res, _ := s.Run()
+	groups := res.ByName("g(a, b)")
+	if len(groups) != 1 || groups[0].State() != StateInterrupted {
+		t.Fatalf("records of the group %+v, want a single interrupted one", groups)
+	}
+	if b := res.ByName("b"); len(b) != 1 || b[0].State() != StateInterrupted {
+		t.Errorf("records of b %+v, want a single interrupted one", b)
+	}
+	if sum := res.Summary; sum.Executed != 0 || sum.Registered != 1 || sum.LargestConsumer != "" {
+		t.Errorf("executed %d of %d, largest consumer %q", sum.Executed, sum.Registered, sum.LargestConsumer)
+	}
+}
+
+func TestNestedSimulator(t *testing.T) {
+	tests := map[string]struct {
+		opts   []Option
+		failed bool
+		done   int
+	}{
+		// a and b take 60 ms each, one after another they exceed the 100 ms of inner
+		"sequential": {failed: true, done: 1},
+		"parallel":   {opts: []Option{WithExecution(Parallel)}, done: 2},
+		// the reservation leaves 50 ms to the processes
+		"parallel reserved": {opts: []Option{WithExecution(Parallel), WithReservedBudget(50)}, failed: true, done: 0},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			inner := NewSimulator("inner", 100, tt.opts...)
+			inner.RegisterFunctions(NewFunction("a").WithTimeout(60), NewFunction("b").WithTimeout(60))
+			s := NewSimulator("outer", 300, WithVirtualClock(), WithVerbosity(Quiet))
+			s.RegisterFunctions(inner.AsProcess(), NewFunction("c").WithTimeout(40))
+			res, _ := s.Run()
+			rows := res.ByName("inner")
+			if len(rows) != 1 || rows[0].Failed != tt.failed {
+				t.Fatalf("records of inner %+v, want a single one failed %v", rows, tt.failed)
+			}
+			done := 0
+			for _, name := range []string{"a", "b"} {
+				if rs := res.ByName(name); len(rs) == 1 && rs[0].State() == StateDone {
+					done++
+				}
+			}
+			if done != tt.done {
+				t.Errorf("%d processes of inner done, want %d", done, tt.done)
+			}
+			if c := res.ByName("c"); len(c) != 1 || c[0].State() != StateDone {
+				t.Errorf("records of c %+v, want a single done one", c)
+			}
+		})
+	}
+}
+
+func TestNestedSimulatorInItself(t *testing.T) {
+	a := NewSimulator("a", 100)
+	if err := a.RegisterFunctions(a.AsProcess()); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("registering a in itself: error %v, want ErrDependencyCycle", err)
+	}
+	b := NewSimulator("b", 100)
+	if err := a.RegisterFunctions(NewSequentialGroup("g", b.AsProcess())); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AddFunctions(a.AsProcess()); !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("registering a in b nested in a: error %v, want ErrDependencyCycle", err)
+	}
+	if ps := b.Processes(); len(ps) != 0 {
+		t.Errorf("b registered %v, want nothing", ps)
+	}
+}
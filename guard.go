@@ -0,0 +1,38 @@
+package t0simulator
+
+import "sync"
+
+// currentProcesses holds the top-level processes in progress in a run, to report the
+// ones the wall-time guard abandoned
+type currentProcesses struct {
+	mu sync.Mutex
+	ps []Proccess
+}
+
+func (c *currentProcesses) add(p Proccess) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ps = append(c.ps, p)
+}
+
+func (c *currentProcesses) remove(p Proccess) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, q := range c.ps {
+		if q == p {
+			c.ps = append(c.ps[:i], c.ps[i+1:]...)
+			return
+		}
+	}
+}
+
+// names returns the names of the processes in progress
+func (c *currentProcesses) names() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := make([]string, len(c.ps))
+	for i, p := range c.ps {
+		names[i] = p.String()
+	}
+	return names
+}
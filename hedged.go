@@ -0,0 +1,143 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Hedged denotes a function simulation hedging slow calls: another attempt is started
+// every hedge delay until one of them finishes
+type Hedged struct {
+	Function
+	latency    LatencySampler
+	hedgeDelay int
+	maxHedges  int
+}
+
+// WithHedging returns a simulated function whose attempts draw their latency from latency.
+// A hedge is started after every hedgeDelay ms without an attempt finishing, up to maxHedges.
+// Hedging is disabled when hedgeDelay or maxHedges is zero. It panics when latency is
+// nil or hedgeDelay or maxHedges are negative.
+func (f Function) WithHedging(latency LatencySampler, hedgeDelay, maxHedges int) *Hedged {
+	if latency == nil {
+		panic(fmt.Sprintf("t0simulator: function %q: nil latency sampler", f.name))
+	}
+	if hedgeDelay < 0 || maxHedges < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative hedge delay %d or max hedges %d", f.name, hedgeDelay, maxHedges))
+	}
+	return &Hedged{
+		Function:   f,
+		latency:    latency,
+		hedgeDelay: hedgeDelay,
+		maxHedges:  maxHedges,
+	}
+}
+
+// attempt denotes a finished attempt of a hedged function
+type attempt struct {
+	n       int
+	latency time.Duration
+}
+
+// Run starts the first attempt and the hedges, it completes when any attempt finishes
+// and cancels the others. Nothing is recorded when ctx expires first.
+func (f *Hedged) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	attemptCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the first attempt to finish wins, finished is closed once it is set
+	var won attempt
+	var once sync.Once
+	finished := make(chan struct{})
+	start := func(n int) {
+		latency := f.latency.Sample()
+		spawn(ctx, func() {
+			t := clockOf(ctx).NewTimer(latency)
+			defer t.Stop()
+			await(ctx, func() bool { return fired(t) || attemptCtx.Err() != nil }, func() {
+				select {
+				case <-t.C():
+					once.Do(func() {
+						won = attempt{n: n, latency: latency}
+						close(finished)
+					})
+				case <-attemptCtx.Done():
+				}
+			})
+		})
+	}
+
+	start(0)
+	hedges := 0
+	delay := time.Duration(f.hedgeDelay) * time.Millisecond
+	var hedge Timer
+	if f.maxHedges > 0 && f.hedgeDelay > 0 {
+		hedge = clockOf(ctx).NewTimer(delay)
+	}
+	defer func() {
+		if hedge != nil {
+			hedge.Stop()
+		}
+	}()
+
+	for done := false; !done; {
+		var fire <-chan time.Time
+		if hedge != nil {
+			fire = hedge.C()
+		}
+		var hedged, expired bool
+		ready := func() bool {
+			return closed(finished) || (hedge != nil && fired(hedge)) || ctx.Err() != nil
+		}
+		await(ctx, ready, func() {
+			select {
+			case <-finished:
+				done = true
+			case <-fire:
+				hedged = true
+			case <-ctx.Done():
+				expired = true
+			}
+		})
+		if expired {
+			return
+		}
+		if hedged {
+			hedges++
+			start(hedges)
+			hedge = nil
+			if hedges < f.maxHedges {
+				hedge = clockOf(ctx).NewTimer(delay)
+			}
+		}
+	}
+	cancel()
+
+	fired := "hedges"
+	if hedges == 1 {
+		fired = "hedge"
+	}
+	f.finish(ctx, w, sp, Record{
+		Timeout:  won.latency,
+		Attempts: hedges + 1,
+		Note:     fmt.Sprintf("%d %s fired, attempt %d won", hedges, fired, won.n+1),
+	})
+}
+
+// IsExecuted returns true if an attempt has finished
+func (f *Hedged) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *Hedged) String() string {
+	return f.name
+}
+
+// Describe returns the hedging configuration of the function
+func (f *Hedged) Describe() string {
+	return fmt.Sprintf("hedge every %dms, up to %d hedges", f.hedgeDelay, f.maxHedges)
+}
@@ -0,0 +1,54 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+// latencies is a LatencySampler returning its latencies in turn
+type latencies []time.Duration
+
+func (l *latencies) Sample() time.Duration {
+	d := (*l)[0]
+	*l = (*l)[1:]
+	return d
+}
+
+func TestHedging(t *testing.T) {
+	for name, tt := range map[string]struct {
+		latencies latencies
+		consumed  time.Duration
+		attempts  int
+		note      string
+	}{
+		"first attempt wins": {latencies{20 * time.Millisecond}, 20 * time.Millisecond, 1, "0 hedges fired, attempt 1 won"},
+		"hedge wins":         {latencies{100 * time.Millisecond, 20 * time.Millisecond}, 50 * time.Millisecond, 2, "1 hedge fired, attempt 2 won"},
+		"max hedges":         {latencies{100 * time.Millisecond, 90 * time.Millisecond, 50 * time.Millisecond}, 100 * time.Millisecond, 3, "2 hedges fired, attempt 1 won"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewSimulator("hedged", 200, WithVirtualClock(), WithVerbosity(Quiet))
+			s.RegisterFunctions(NewFunction("a").WithHedging(&tt.latencies, 30, 2))
+			res, err := s.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := res.Records[0]
+			if !rec.Executed || rec.Consumed != tt.consumed || rec.Attempts != tt.attempts || rec.Note != tt.note {
+				t.Errorf("record %+v, want %d attempts consuming %v noted %q", rec, tt.attempts, tt.consumed, tt.note)
+			}
+		})
+	}
+}
+
+func TestHedgingPastDeadline(t *testing.T) {
+	l := latencies{100 * time.Millisecond, 100 * time.Millisecond}
+	s := NewSimulator("hedged", 50, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(NewFunction("a").WithHedging(&l, 30, 1))
+	res, _ := s.Run()
+	if !res.TimedOut {
+		t.Fatal("run did not time out")
+	}
+	if a := res.ByName("a"); len(a) != 1 || a[0].Executed {
+		t.Errorf("records of a %+v, want a single unexecuted one", a)
+	}
+}
@@ -0,0 +1,118 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// HistogramSampler denotes a LatencySampler drawing latencies from a recorded histogram
+type HistogramSampler struct {
+	bounds []int
+	// cumulative holds the running total of the counts of the buckets
+	cumulative []int64
+	random     random
+}
+
+// NewHistogramSampler returns a sampler drawing latencies from the histogram whose i-th
+// bucket counts the latencies in (bounds[i-1],bounds[i]] ms, the first bucket starting
+// at 0. Latencies are interpolated uniformly within a bucket. It returns an error when
+// the bounds are negative or not strictly increasing, when a count is negative or when
+// the histogram is empty.
+func NewHistogramSampler(bounds []int, counts []int64) (*HistogramSampler, error) {
+	if len(bounds) != len(counts) {
+		return nil, fmt.Errorf("t0simulator: histogram: %d bounds for %d counts", len(bounds), len(counts))
+	}
+	cumulative := make([]int64, len(counts))
+	var total int64
+	for i, bound := range bounds {
+		if bound < 0 {
+			return nil, fmt.Errorf("t0simulator: histogram: negative bound %d", bound)
+		}
+		if i > 0 && bound <= bounds[i-1] {
+			return nil, fmt.Errorf("t0simulator: histogram: bound %d not greater than %d", bound, bounds[i-1])
+		}
+		if counts[i] < 0 {
+			return nil, fmt.Errorf("t0simulator: histogram: negative count %d for bound %d", counts[i], bound)
+		}
+		total += counts[i]
+		cumulative[i] = total
+	}
+	if total == 0 {
+		return nil, errors.New("t0simulator: histogram: no latency recorded")
+	}
+	return &HistogramSampler{
+		bounds:     append([]int(nil), bounds...),
+		cumulative: cumulative,
+	}, nil
+}
+
+// WithSource sets the source latencies are drawn from, the default source of math/rand is used otherwise
+func (h *HistogramSampler) WithSource(src rand.Source) *HistogramSampler {
+	h.random.setSource(src)
+	return h
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (h *HistogramSampler) WithSeed(seed int64) *HistogramSampler {
+	return h.WithSource(rand.NewSource(seed))
+}
+
+// Sample returns a latency drawn from the histogram
+func (h *HistogramSampler) Sample() time.Duration {
+	total := h.cumulative[len(h.cumulative)-1]
+	u := h.random.float64() * float64(total)
+	i := sort.Search(len(h.cumulative), func(i int) bool {
+		return float64(h.cumulative[i]) > u
+	})
+	from, count := 0, h.cumulative[i]
+	if i > 0 {
+		from, count = h.bounds[i-1], h.cumulative[i]-h.cumulative[i-1]
+		u -= float64(h.cumulative[i-1])
+	}
+	sample := float64(from) + u/float64(count)*float64(h.bounds[i]-from)
+	return time.Duration(sample * float64(time.Millisecond))
+}
+
+// FunctionWithSampledLatency denotes a function simulation whose latency is drawn
+// from a LatencySampler on every run
+type FunctionWithSampledLatency struct {
+	Function
+	sampler LatencySampler
+}
+
+// WithLatency returns a simulated function sleeping a duration drawn from sampler on
+// every run, e.g. a HistogramSampler. It panics when sampler is nil.
+func (f Function) WithLatency(sampler LatencySampler) *FunctionWithSampledLatency {
+	if sampler == nil {
+		panic(fmt.Sprintf("t0simulator: function %q: nil latency sampler", f.name))
+	}
+	return &FunctionWithSampledLatency{
+		Function: f,
+		sampler:  sampler,
+	}
+}
+
+// Sample returns a latency drawn from the sampler of the function
+func (f *FunctionWithSampledLatency) Sample() time.Duration {
+	return f.sampler.Sample()
+}
+
+// Run runs the function
+func (f *FunctionWithSampledLatency) Run(ctx context.Context, w io.Writer) {
+	latency := f.Sample()
+	f.sleep(ctx, w, latency, Record{Timeout: latency})
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithSampledLatency) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithSampledLatency) String() string {
+	return f.name
+}
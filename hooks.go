@@ -0,0 +1,176 @@
+package t0simulator
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// hooks holds the optional callbacks invoked during a run
+type hooks struct {
+	onProcessStart func(name string, remaining int64)
+	onProcessDone  func(name string, consumed, remaining int64)
+	onTimeout      func(unexecuted []string)
+}
+
+// WithOnProcessStart sets a callback invoked before each process starts
+func WithOnProcessStart(fn func(name string, remaining int64)) Option {
+	return func(s *Simulator) error {
+		s.hooks.onProcessStart = fn
+		return nil
+	}
+}
+
+// WithOnProcessDone sets a callback invoked after each process is done
+func WithOnProcessDone(fn func(name string, consumed, remaining int64)) Option {
+	return func(s *Simulator) error {
+		s.hooks.onProcessDone = fn
+		return nil
+	}
+}
+
+// WithOnTimeout sets a callback invoked with the unexecuted functions when the deadline is reached
+func WithOnTimeout(fn func(unexecuted []string)) Option {
+	return func(s *Simulator) error {
+		s.hooks.onTimeout = fn
+		return nil
+	}
+}
+
+// EventKind denotes the kind of an Event
+type EventKind int
+
+const (
+	// EventStart is sent before a process starts
+	EventStart EventKind = iota
+	// EventDone is sent after a process is done
+	EventDone
+	// EventTimeout is sent when the deadline is reached
+	EventTimeout
+	// EventFinished is sent when the run is finished, right before the channel is closed
+	EventFinished
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventStart:
+		return "start"
+	case EventDone:
+		return "done"
+	case EventTimeout:
+		return "timeout"
+	case EventFinished:
+		return "finished"
+	}
+	return "unknown"
+}
+
+// Event denotes a progress event of a run. Name is the process name for start and
+// done events and the simulator name for timeout and finished events.
+type Event struct {
+	Kind      EventKind
+	Name      string
+	Remaining int64
+}
+
+// eventBuffer is the capacity of the channel returned by Simulator.Events
+const eventBuffer = 128
+
+// hookRunner invokes the hooks and sends the events of a single run synchronously
+// and in order, and stops once the run has timed out or finished. It is given
+// durations, the hooks and the events get them in whole milliseconds.
+type hookRunner struct {
+	mu     sync.Mutex
+	name   string
+	hooks  hooks
+	events chan Event
+	closed bool
+
+	// progress receives a line every time a process is done
+	progress io.Writer
+	total    int
+	done     int
+}
+
+// send sends e without blocking, the event is dropped when the consumer is too slow
+func (h *hookRunner) send(e Event) {
+	if h.events == nil {
+		return
+	}
+	select {
+	case h.events <- e:
+	default:
+	}
+}
+
+func (h *hookRunner) processStart(name string, remaining time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if h.hooks.onProcessStart != nil {
+		h.hooks.onProcessStart(name, remaining.Milliseconds())
+	}
+	h.send(Event{Kind: EventStart, Name: name, Remaining: remaining.Milliseconds()})
+}
+
+func (h *hookRunner) processDone(name string, consumed, remaining time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if h.hooks.onProcessDone != nil {
+		h.hooks.onProcessDone(name, consumed.Milliseconds(), remaining.Milliseconds())
+	}
+	h.send(Event{Kind: EventDone, Name: name, Remaining: remaining.Milliseconds()})
+	h.done++
+	if h.progress != nil {
+		fmt.Fprintf(h.progress, "%d/%d done, %dms remaining\n", h.done, h.total, remaining.Milliseconds())
+	}
+}
+
+// timeout invokes the timeout hook and closes the runner, so no process hook
+// or event can follow it
+func (h *hookRunner) timeout(unexecuted []string, remaining time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if h.hooks.onTimeout != nil {
+		h.hooks.onTimeout(unexecuted)
+	}
+	h.send(Event{Kind: EventTimeout, Name: h.name, Remaining: remaining.Milliseconds()})
+	if h.progress != nil {
+		fmt.Fprintf(h.progress, "time out reached, %d/%d done\n", h.done, h.total)
+	}
+	h.closed = true
+}
+
+// finish closes the runner and sends the finished event before closing the event channel
+func (h *hookRunner) finish(remaining time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.send(Event{Kind: EventFinished, Name: h.name, Remaining: remaining.Milliseconds()})
+	h.close()
+}
+
+// abort closes the runner and the event channel without any event, for a run that
+// returned before starting
+func (h *hookRunner) abort() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.close()
+}
+
+// close closes the runner and the event channel, h.mu must be locked
+func (h *hookRunner) close() {
+	h.closed = true
+	if h.events != nil {
+		close(h.events)
+		h.events = nil
+	}
+}
@@ -0,0 +1,111 @@
+package t0simulator
+
+import (
+	"html/template"
+	"io"
+	"math"
+	"time"
+)
+
+var htmlReportTemplate = template.Must(template.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>SIMULATOR:{{.Name}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+tr.unexecuted td { color: #999; }
+.timeline { position: relative; border-left: 1px solid #ccc; }
+.lane { position: relative; height: 22px; margin: 4px 0; }
+.bar { position: absolute; height: 100%; background: #4a90d9; }
+.lane.unexecuted .bar { background: #ddd; }
+.label { position: absolute; left: 4px; line-height: 22px; font-size: 12px; white-space: nowrap; }
+.deadline { position: absolute; top: 0; bottom: 0; width: 2px; background: #d0021b; }
+.deadline-row { height: 1px; margin: 8px 0; border-top: 2px dashed #d0021b; }
+</style>
+</head>
+<body>
+<h1>SIMULATOR:{{.Name}}</h1>
+<table>
+<tr><th>Name</th><th>Max Timeout(ms)</th><th>Consumed(ms)</th><th>Remaining(ms)</th></tr>
+<tr><td>Init</td><td>{{.Budget}}</td><td>0</td><td>{{.Budget}}</td></tr>
+{{range .Rows}}<tr{{if not .Executed}} class="unexecuted"{{end}}><td>{{.Name}}</td>{{if .Executed}}<td>{{.Timeout}}</td><td>{{.Consumed}}</td><td>{{.Remaining}}</td>{{else}}<td colspan="3">unexecuted</td>{{end}}</tr>
+{{end}}</table>
+<p><strong>{{.Outcome}}</strong></p>
+<div class="timeline">
+<div class="deadline" style="left: {{.DeadlineAt}}%" title="deadline {{.Budget}}ms"></div>
+{{range .Rows}}{{if .Executed}}<div class="lane"><div class="bar" style="left: {{.Left}}%; width: {{.Width}}%"></div><span class="label">{{.Name}} ({{.Consumed}}ms)</span></div>
+{{end}}{{end}}{{if .Unexecuted}}<div class="deadline-row"></div>
+{{range .Rows}}{{if not .Executed}}<div class="lane unexecuted"><span class="label">{{.Name}}</span></div>
+{{end}}{{end}}{{end}}</div>
+</body>
+</html>
+`))
+
+type htmlRow struct {
+	Name                         string
+	Executed                     bool
+	Timeout, Consumed, Remaining int64
+	Left, Width                  float64
+}
+
+type htmlReport struct {
+	Name       string
+	Budget     int64
+	Outcome    string
+	DeadlineAt float64
+	Rows       []htmlRow
+	Unexecuted bool
+}
+
+// HTML writes the result as a self-contained HTML page to w: a table of the rows and
+// a bar per process sized by its consumption against the budget, with a red marker
+// at the deadline. Unexecuted processes are greyed out below the marker.
+func (r *Result) HTML(w io.Writer) error {
+	scale := r.Budget
+	for _, rec := range r.Records {
+		if rec.Executed && rec.End > scale {
+			scale = rec.End
+		}
+	}
+	percent := func(d time.Duration) float64 {
+		if scale <= 0 {
+			return 0
+		}
+		return math.Round(float64(d)/float64(scale)*10000) / 100
+	}
+
+	report := htmlReport{
+		Name:       r.Name,
+		Budget:     r.Budget.Milliseconds(),
+		Outcome:    "Done with time left " + ms(r.Remaining) + " ms",
+		DeadlineAt: percent(r.Budget),
+	}
+	switch {
+	case r.Abandoned:
+		report.Outcome = guardNote(r)
+	case r.TimedOut:
+		report.Outcome = "Time out reached"
+	case r.Cancelled:
+		report.Outcome = "Cancelled by caller"
+	case r.Aborted:
+		report.Outcome = "Aborted after a panic"
+	}
+	for _, rec := range r.Records {
+		row := htmlRow{Name: rec.Name, Executed: rec.Executed}
+		if rec.Executed {
+			row.Timeout = rec.Timeout.Milliseconds()
+			row.Consumed = rec.Consumed.Milliseconds()
+			row.Remaining = rec.Remaining.Milliseconds()
+			row.Left = percent(rec.Start)
+			row.Width = percent(rec.Consumed)
+		} else {
+			report.Unexecuted = true
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return htmlReportTemplate.Execute(w, report)
+}
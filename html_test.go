@@ -0,0 +1,36 @@
+package t0simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	s := NewSimulator("<b>report</b>", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("<script>alert(1)</script>").WithTimeout(30),
+		NewFunction("b").WithTimeout(90),
+	)
+	res, _ := s.Run()
+	var buf bytes.Buffer
+	if err := res.HTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		"<title>SIMULATOR:&lt;b&gt;report&lt;/b&gt;</title>",
+		"<td>&lt;script&gt;alert(1)&lt;/script&gt;</td><td>30</td><td>30</td><td>70</td>",
+		`<tr class="unexecuted"><td>b</td><td colspan="3">unexecuted</td></tr>`,
+		`<div class="deadline" style="left: 100%"`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page without %s\n%s", want, page)
+		}
+	}
+	for _, unescaped := range []string{"<script>", "<b>"} {
+		if strings.Contains(page, unescaped) {
+			t.Errorf("page with %s unescaped", unescaped)
+		}
+	}
+}
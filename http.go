@@ -0,0 +1,127 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// HTTPProcess denotes a real HTTP GET run through the simulator, the request carries
+// the context of the run so its deadline is propagated
+type HTTPProcess struct {
+	Function
+	url     string
+	handler http.Handler
+	reuse   bool
+
+	mu     sync.Mutex
+	server *httptest.Server
+	client *http.Client
+}
+
+// NewHTTPProcess returns a process sending a GET request to url
+func NewHTTPProcess(name, url string) *HTTPProcess {
+	return &HTTPProcess{Function: NewFunction(name), url: url, reuse: true}
+}
+
+// NewHTTPHandlerProcess returns a process sending a GET request to an httptest.Server
+// serving handler, so no network is needed. The server is started on the first run
+// and stopped by Close.
+func NewHTTPHandlerProcess(name string, handler http.Handler) *HTTPProcess {
+	return &HTTPProcess{Function: NewFunction(name), handler: handler, reuse: true}
+}
+
+// WithConnectionReuse sets whether connections are kept alive across runs, which is the
+// default, or a new connection is opened on every run
+func (p *HTTPProcess) WithConnectionReuse(reuse bool) *HTTPProcess {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reuse = reuse
+	p.client = nil
+	return p
+}
+
+// Run sends the request and records its status code and the time it took, the row is
+// failed when the request fails or was cut off by the deadline
+func (p *HTTPProcess) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	done := p.track(ctx, sp, 0)
+	code, err := p.get(ctx)
+	done()
+	rec := Record{Timeout: since(ctx, sp.startedAt), StatusCode: code, Failed: err != nil, Err: err}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		rec.Note = "deadline exceeded"
+	case err != nil:
+		rec.Note = err.Error()
+	default:
+		rec.Note = fmt.Sprintf("HTTP %d", code)
+	}
+	p.finish(ctx, w, sp, rec)
+}
+
+// get sends the request and drains the response body, so the connection can be reused
+func (p *HTTPProcess) get(ctx context.Context) (int, error) {
+	url, client := p.target()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+// target returns the URL and the client of the request, starting the server when needed
+func (p *HTTPProcess) target() (string, *http.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.handler != nil && p.server == nil {
+		p.server = httptest.NewServer(p.handler)
+		p.url = p.server.URL
+	}
+	if p.client == nil {
+		p.client = &http.Client{Transport: &http.Transport{DisableKeepAlives: !p.reuse}}
+	}
+	return p.url, p.client
+}
+
+// Close stops the server of a handler process and closes the idle connections
+func (p *HTTPProcess) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		p.client.CloseIdleConnections()
+	}
+	if p.server != nil {
+		p.server.Close()
+		p.server = nil
+	}
+}
+
+// IsExecuted returns true if the request has completed
+func (p *HTTPProcess) IsExecuted() bool {
+	return p.isExecuted.get()
+}
+
+func (p *HTTPProcess) String() string {
+	return p.name
+}
+
+// Describe returns the target of the request
+func (p *HTTPProcess) Describe() string {
+	if p.handler != nil {
+		return "GET handler"
+	}
+	return "GET " + p.url
+}
@@ -0,0 +1,79 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// connections is a handler counting the client connections its requests came from
+type connections struct {
+	mu    sync.Mutex
+	addrs map[string]bool
+	delay time.Duration
+}
+
+func (c *connections) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.addrs[r.RemoteAddr] = true
+	c.mu.Unlock()
+	select {
+	case <-time.After(c.delay):
+		w.WriteHeader(http.StatusTeapot)
+	case <-r.Context().Done():
+	}
+}
+
+func TestHTTPProcess(t *testing.T) {
+	for _, reuse := range []bool{true, false} {
+		h := &connections{addrs: map[string]bool{}}
+		p := NewHTTPHandlerProcess("get", h).WithConnectionReuse(reuse)
+		defer p.Close()
+		s := NewSimulator("http", 1000, WithVerbosity(Quiet))
+		s.RegisterFunctions(p)
+		for run := 1; run <= 2; run++ {
+			res, err := s.Run()
+			if err != nil {
+				t.Fatalf("reuse %t, run %d: %v", reuse, run, err)
+			}
+			rec := res.Records[0]
+			if !rec.Executed || rec.Failed || rec.StatusCode != http.StatusTeapot || rec.Note != "HTTP 418" {
+				t.Errorf("reuse %t, run %d: record %+v, want a successful 418", reuse, run, rec)
+			}
+			if rec.Elapsed <= 0 || rec.Consumed <= 0 {
+				t.Errorf("reuse %t, run %d: elapsed %v, consumed %v", reuse, run, rec.Elapsed, rec.Consumed)
+			}
+		}
+		want := 2
+		if reuse {
+			want = 1
+		}
+		if len(h.addrs) != want {
+			t.Errorf("reuse %t: %d connections, want %d", reuse, len(h.addrs), want)
+		}
+	}
+}
+
+func TestHTTPProcessPastDeadline(t *testing.T) {
+	for _, reuse := range []bool{true, false} {
+		h := &connections{addrs: map[string]bool{}, delay: time.Second}
+		p := NewHTTPHandlerProcess("get", h).WithConnectionReuse(reuse)
+		defer p.Close()
+		s := NewSimulator("http", 50, WithVerbosity(Quiet))
+		s.RegisterFunctions(p)
+		res, _ := s.Run()
+		if !res.TimedOut {
+			t.Errorf("reuse %t: run did not time out", reuse)
+		}
+		rec := res.Records[0]
+		if !rec.Failed || !errors.Is(rec.Err, context.DeadlineExceeded) || rec.Note != "deadline exceeded" {
+			t.Errorf("reuse %t: record %+v, want one cut off by the deadline", reuse, rec)
+		}
+		if rec.Elapsed < 50*time.Millisecond || rec.Elapsed >= h.delay {
+			t.Errorf("reuse %t: elapsed %v, want the budget", reuse, rec.Elapsed)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package t0simulator
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Report denotes the JSON document of a simulator run
+type Report struct {
+	Name           string          `json:"name"`
+	Budget         int64           `json:"budget_ms"`
+	Reserved       int64           `json:"reserved_ms,omitempty"`
+	Overdraft      int64           `json:"overdraft_ms,omitempty"`
+	Normalized     float64         `json:"weights_normalized,omitempty"`
+	StartedAt      time.Time       `json:"started_at"`
+	Deadline       *time.Time      `json:"deadline,omitempty"`
+	Processes      []ProcessReport `json:"processes"`
+	TimedOut       bool            `json:"timed_out"`
+	Parallel       bool            `json:"parallel,omitempty"`
+	Cancelled      bool            `json:"cancelled,omitempty"`
+	Aborted        bool            `json:"aborted,omitempty"`
+	Abandoned      bool            `json:"abandoned,omitempty"`
+	Stuck          []string        `json:"stuck,omitempty"`
+	Seed           *int64          `json:"seed,omitempty"`
+	SeedRun        int             `json:"seed_run,omitempty"`
+	Paused         int64           `json:"paused_ms,omitempty"`
+	PausedConsumed int64           `json:"paused_consumed_ms,omitempty"`
+	Remaining      int64           `json:"remaining_ms"`
+	Unexecuted     []string        `json:"unexecuted,omitempty"`
+	Summary        SummaryReport   `json:"summary"`
+}
+
+// SummaryReport denotes the JSON object of the budget usage in a Report
+type SummaryReport struct {
+	Consumed        int64    `json:"consumed_ms"`
+	Utilization     float64  `json:"utilization_percent"`
+	LargestConsumer string   `json:"largest_consumer,omitempty"`
+	LargestConsumed int64    `json:"largest_consumed_ms"`
+	Makespan        int64    `json:"makespan_ms"`
+	CriticalPath    string   `json:"critical_path,omitempty"`
+	Overhead        int64    `json:"overhead_ms,omitempty"`
+	Dependencies    int64    `json:"dependencies_ms,omitempty"`
+	Executed        int      `json:"executed"`
+	Failed          int      `json:"failed,omitempty"`
+	Skipped         int      `json:"skipped,omitempty"`
+	Partial         int      `json:"partial,omitempty"`
+	Registered      int      `json:"registered"`
+	Violations      []string `json:"violations,omitempty"`
+}
+
+// ProcessReport denotes the JSON object of a single process in a Report
+type ProcessReport struct {
+	Name       string  `json:"name"`
+	Timeout    int64   `json:"timeout_ms"`
+	Weight     float64 `json:"weight,omitempty"`
+	Available  int64   `json:"available_ms"`
+	Planned    int64   `json:"planned_ms,omitempty"`
+	Consumed   int64   `json:"consumed_ms"`
+	Wait       int64   `json:"wait_ms,omitempty"`
+	Service    int64   `json:"service_ms,omitempty"`
+	Cleanup    int64   `json:"cleanup_ms,omitempty"`
+	Remaining  int64   `json:"remaining_ms"`
+	Executed   bool    `json:"executed"`
+	InProgress bool    `json:"in_progress,omitempty"`
+	Status     Status  `json:"status"`
+	State      State   `json:"state"`
+	Priority   int     `json:"priority,omitempty"`
+	Escalated  bool    `json:"escalated,omitempty"`
+	Demoted    bool    `json:"demoted,omitempty"`
+	Clamped    bool    `json:"clamped,omitempty"`
+	Attempts   int     `json:"attempts,omitempty"`
+	Failed     bool    `json:"failed,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	Stack      string  `json:"stack,omitempty"`
+	StatusCode int     `json:"status_code,omitempty"`
+	Skipped    bool    `json:"skipped,omitempty"`
+	Overhead   bool    `json:"overhead,omitempty"`
+	Violation  string  `json:"violation,omitempty"`
+	Delivered  int     `json:"delivered,omitempty"`
+	Chunks     int     `json:"chunks,omitempty"`
+	Outcome    string  `json:"outcome,omitempty"`
+	Depth      int     `json:"depth,omitempty"`
+	Path       []int   `json:"path,omitempty"`
+	Note       string  `json:"note,omitempty"`
+	// Start, End and FinishedAt are omitted for unexecuted processes
+	Start      *int64     `json:"start_ms,omitempty"`
+	End        *int64     `json:"end_ms,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Elapsed and Drift are the measured time of the process and its drift from Timeout
+	Elapsed int64   `json:"elapsed_ms,omitempty"`
+	Drift   float64 `json:"drift_ms,omitempty"`
+}
+
+// Report returns the JSON document of the result
+func (r *Result) Report() Report {
+	report := Report{
+		Name:           r.Name,
+		Budget:         r.Budget.Milliseconds(),
+		Reserved:       r.Reserved.Milliseconds(),
+		Overdraft:      r.Overdraft.Milliseconds(),
+		Normalized:     r.Normalized,
+		StartedAt:      r.StartedAt,
+		Processes:      make([]ProcessReport, 0, len(r.Records)),
+		TimedOut:       r.TimedOut,
+		Parallel:       r.Execution == Parallel,
+		Cancelled:      r.Cancelled,
+		Aborted:        r.Aborted,
+		Abandoned:      r.Abandoned,
+		Stuck:          r.Stuck,
+		Remaining:      r.Remaining.Milliseconds(),
+		Paused:         r.Paused.Milliseconds(),
+		PausedConsumed: r.PausedConsumed.Milliseconds(),
+		Summary: SummaryReport{
+			Consumed:        r.Summary.Consumed.Milliseconds(),
+			Utilization:     r.Summary.Utilization,
+			LargestConsumer: r.Summary.LargestConsumer,
+			LargestConsumed: r.Summary.LargestConsumed.Milliseconds(),
+			Makespan:        r.Summary.Makespan.Milliseconds(),
+			CriticalPath:    r.Summary.CriticalPath,
+			Overhead:        r.Summary.Overhead.Milliseconds(),
+			Dependencies:    r.Summary.Dependencies.Milliseconds(),
+			Executed:        r.Summary.Executed,
+			Failed:          r.Summary.Failed,
+			Skipped:         r.Summary.Skipped,
+			Partial:         r.Summary.Partial,
+			Registered:      r.Summary.Registered,
+			Violations:      r.Summary.Violations,
+		},
+	}
+	if !r.Deadline.IsZero() {
+		report.Deadline = &r.Deadline
+	}
+	if r.SeedRun > 0 {
+		report.Seed, report.SeedRun = &r.Seed, r.SeedRun
+	}
+	for _, rec := range r.Records {
+		p := ProcessReport{
+			Name:       rec.Name,
+			Timeout:    rec.Timeout.Milliseconds(),
+			Weight:     rec.Weight,
+			Available:  rec.Available.Milliseconds(),
+			Planned:    rec.Planned.Milliseconds(),
+			Consumed:   rec.Consumed.Milliseconds(),
+			Wait:       rec.Wait.Milliseconds(),
+			Service:    rec.Service.Milliseconds(),
+			Cleanup:    rec.Cleanup.Milliseconds(),
+			Remaining:  rec.Remaining.Milliseconds(),
+			Executed:   rec.Executed,
+			InProgress: rec.InProgress,
+			Status:     rec.Status(),
+			State:      rec.State(),
+			Priority:   int(rec.Priority),
+			Escalated:  rec.Escalated,
+			Demoted:    rec.Demoted,
+			Clamped:    rec.Clamped,
+			Attempts:   rec.Attempts,
+			Failed:     rec.Failed,
+			Skipped:    rec.Skipped,
+			Overhead:   rec.Overhead,
+			Violation:  rec.Violation,
+			Delivered:  rec.Delivered,
+			Chunks:     rec.Chunks,
+			Outcome:    rec.Outcome,
+			Depth:      rec.Depth,
+			Path:       rec.Path,
+			Note:       rec.Note,
+		}
+		if rec.Err != nil {
+			p.Error = rec.Err.Error()
+		}
+		if err, ok := rec.Err.(*PanicError); ok {
+			p.Stack = string(err.Stack)
+		}
+		p.StatusCode = rec.StatusCode
+		p.Elapsed = rec.Elapsed.Milliseconds()
+		p.Drift = float64(rec.Drift().Microseconds()) / 1000
+		if rec.Executed {
+			start, end, finishedAt := rec.Start.Milliseconds(), rec.End.Milliseconds(), rec.FinishedAt
+			p.Start, p.End, p.FinishedAt = &start, &end, &finishedAt
+		}
+		report.Processes = append(report.Processes, p)
+	}
+	if r.TimedOut || r.Cancelled || r.Aborted || r.Abandoned {
+		report.Unexecuted = r.Unexecuted()
+	}
+	return report
+}
+
+// WriteJSON writes the result as an indented JSON document to w
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Report())
+}
@@ -0,0 +1,61 @@
+package t0simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	s := NewSimulator("json", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewFunction("b").WithTimeout(40).WithFailureRate(1),
+		NewFunction("c").WithTimeout(50),
+	)
+	res, _ := s.Run()
+	var buf bytes.Buffer
+	if err := res.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got Report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Name != "json" || got.Budget != 100 || !got.TimedOut || !got.StartedAt.Equal(res.StartedAt) {
+		t.Errorf("run %q, budget %d ms, timed out %v, started at %v", got.Name, got.Budget, got.TimedOut, got.StartedAt)
+	}
+	if !slices.Equal(got.Unexecuted, []string{"c"}) {
+		t.Errorf("unexecuted %v, want [c]", got.Unexecuted)
+	}
+	if sum := got.Summary; sum.Consumed != 100 || sum.Executed != 2 || sum.Failed != 1 || sum.Registered != 3 {
+		t.Errorf("summary %+v", sum)
+	}
+	want := []struct {
+		name              string
+		timeout, consumed int64
+		state             State
+		failed            bool
+	}{
+		{"a", 30, 30, StateDone, false},
+		{"b", 40, 40, StateDone, true},
+		{"c", 50, 30, StateInterrupted, false},
+	}
+	if len(got.Processes) != len(want) {
+		t.Fatalf("%d processes, want %d", len(got.Processes), len(want))
+	}
+	for i, w := range want {
+		p := got.Processes[i]
+		if p.Name != w.name || p.Timeout != w.timeout || p.Consumed != w.consumed || p.State != w.state || p.Failed != w.failed {
+			t.Errorf("process %d: %+v, want %+v", i, p, w)
+		}
+		if executed := p.Start != nil && p.End != nil && p.FinishedAt != nil; executed != p.Executed {
+			t.Errorf("process %s: start %v, end %v, finished at %v, executed %v", p.Name, p.Start, p.End, p.FinishedAt, p.Executed)
+		}
+	}
+	if b := got.Processes[1]; *b.Start != 30 || *b.End != 70 || b.Status != StatusFailed {
+		t.Errorf("b from %d to %d ms %s, want failed from 30 to 70 ms", *b.Start, *b.End, b.Status)
+	}
+}
@@ -0,0 +1,263 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// LatencySampler denotes a latency distribution. The random latency functions are
+// samplers themselves, so they can be used as the distribution of other processes.
+type LatencySampler interface {
+	Sample() time.Duration
+}
+
+// fixedLatency is a LatencySampler always returning the same latency
+type fixedLatency time.Duration
+
+// FixedLatency returns a LatencySampler always returning ms
+func FixedLatency(ms int) LatencySampler {
+	return fixedLatency(time.Duration(ms) * time.Millisecond)
+}
+
+func (l fixedLatency) Sample() time.Duration {
+	return time.Duration(l)
+}
+
+// FunctionWithRandomLatency denotes a function simulation whose latency is drawn
+// uniformly from a range on every run
+type FunctionWithRandomLatency struct {
+	Function
+	min, max int
+	random   random
+}
+
+// WithRandomLatency returns a simulated function sleeping a duration drawn uniformly
+// from [min,max] ms, it panics when the bounds are negative or min is greater than max
+func (f Function) WithRandomLatency(min, max int) *FunctionWithRandomLatency {
+	if min < 0 || max < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative latency bounds [%d,%d]", f.name, min, max))
+	}
+	if min > max {
+		panic(fmt.Sprintf("t0simulator: function %q: latency min %d greater than max %d", f.name, min, max))
+	}
+	return &FunctionWithRandomLatency{
+		Function: f,
+		min:      min,
+		max:      max,
+	}
+}
+
+// WithSource sets the source latencies are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithRandomLatency) WithSource(src rand.Source) *FunctionWithRandomLatency {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithRandomLatency) WithSeed(seed int64) *FunctionWithRandomLatency {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// Sample returns a latency drawn from the range of the function
+func (f *FunctionWithRandomLatency) Sample() time.Duration {
+	return time.Duration(f.min+int(f.random.int63n(int64(f.max-f.min+1)))) * time.Millisecond
+}
+
+// Run runs the function
+func (f *FunctionWithRandomLatency) Run(ctx context.Context, w io.Writer) {
+	latency := f.Sample()
+	f.sleep(ctx, w, latency, Record{Timeout: latency})
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithRandomLatency) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithRandomLatency) String() string {
+	return f.name
+}
+
+// Describe returns the latency range of the function
+func (f *FunctionWithRandomLatency) Describe() string {
+	return fmt.Sprintf("uniform %d-%dms", f.min, f.max)
+}
+
+// FunctionWithNormalLatency denotes a function simulation whose latency is drawn
+// from a normal distribution on every run
+type FunctionWithNormalLatency struct {
+	Function
+	mean, stddev float64
+	random       random
+}
+
+// WithNormalLatency returns a simulated function sleeping a duration drawn from a normal
+// distribution of mean and stddev ms, clamped at zero. It panics when stddev is negative.
+func (f Function) WithNormalLatency(mean, stddev float64) *FunctionWithNormalLatency {
+	if stddev < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative latency stddev %v", f.name, stddev))
+	}
+	return &FunctionWithNormalLatency{
+		Function: f,
+		mean:     mean,
+		stddev:   stddev,
+	}
+}
+
+// WithSource sets the source latencies are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithNormalLatency) WithSource(src rand.Source) *FunctionWithNormalLatency {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithNormalLatency) WithSeed(seed int64) *FunctionWithNormalLatency {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// Sample returns a latency drawn from the distribution of the function
+func (f *FunctionWithNormalLatency) Sample() time.Duration {
+	sample := f.mean + f.random.normFloat64()*f.stddev
+	if sample < 0 {
+		sample = 0
+	}
+	return time.Duration(sample * float64(time.Millisecond))
+}
+
+// Run runs the function
+func (f *FunctionWithNormalLatency) Run(ctx context.Context, w io.Writer) {
+	latency := f.Sample()
+	f.sleep(ctx, w, latency, Record{Timeout: latency})
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithNormalLatency) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+// String returns the name of the function followed by its distribution
+func (f *FunctionWithNormalLatency) String() string {
+	return fmt.Sprintf("%s (%s)", f.name, f.Describe())
+}
+
+// Describe returns the latency distribution of the function
+func (f *FunctionWithNormalLatency) Describe() string {
+	return fmt.Sprintf("normal %v±%vms", f.mean, f.stddev)
+}
+
+// Percentile buckets a latency drawn from a profile falls into
+const (
+	BucketP50  = "p0-p50"
+	BucketP95  = "p50-p95"
+	BucketP99  = "p95-p99"
+	BucketTail = "p99+"
+)
+
+// FunctionWithLatencyProfile denotes a function simulation whose latency is drawn
+// from a profile of percentiles on every run
+type FunctionWithLatencyProfile struct {
+	Function
+	p50, p95, p99 int
+	random        random
+
+	mu      sync.Mutex
+	buckets map[string]int
+}
+
+// WithLatencyProfile returns a simulated function sleeping a duration matching the
+// p50, p95 and p99 percentiles in ms. Latencies are interpolated linearly between
+// 0 and p50, p50 and p95, p95 and p99, and drawn from an exponential tail beyond p99.
+// It panics when the percentiles are negative or not monotonic.
+func (f Function) WithLatencyProfile(p50, p95, p99 int) *FunctionWithLatencyProfile {
+	if p50 < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative p50 %d", f.name, p50))
+	}
+	if p50 > p95 || p95 > p99 {
+		panic(fmt.Sprintf("t0simulator: function %q: percentiles p50 %d, p95 %d, p99 %d are not monotonic", f.name, p50, p95, p99))
+	}
+	return &FunctionWithLatencyProfile{
+		Function: f,
+		p50:      p50,
+		p95:      p95,
+		p99:      p99,
+		buckets:  make(map[string]int),
+	}
+}
+
+// WithSource sets the source latencies are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithLatencyProfile) WithSource(src rand.Source) *FunctionWithLatencyProfile {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithLatencyProfile) WithSeed(seed int64) *FunctionWithLatencyProfile {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// sample returns a latency in ms and the percentile bucket it fell into
+func (f *FunctionWithLatencyProfile) sample() (float64, string) {
+	interpolate := func(u, fromU, toU float64, from, to int) float64 {
+		return float64(from) + (u-fromU)/(toU-fromU)*float64(to-from)
+	}
+	u := f.random.float64()
+	switch {
+	case u < 0.50:
+		return interpolate(u, 0, 0.50, 0, f.p50), BucketP50
+	case u < 0.95:
+		return interpolate(u, 0.50, 0.95, f.p50, f.p95), BucketP95
+	case u < 0.99:
+		return interpolate(u, 0.95, 0.99, f.p95, f.p99), BucketP99
+	}
+	return float64(f.p99) + f.random.expFloat64()*float64(f.p99-f.p95), BucketTail
+}
+
+// draw returns a latency and counts the percentile bucket it fell into
+func (f *FunctionWithLatencyProfile) draw() (time.Duration, string) {
+	sample, bucket := f.sample()
+	f.mu.Lock()
+	f.buckets[bucket]++
+	f.mu.Unlock()
+	return time.Duration(sample * float64(time.Millisecond)), bucket
+}
+
+// Sample returns a latency drawn from the profile of the function
+func (f *FunctionWithLatencyProfile) Sample() time.Duration {
+	latency, _ := f.draw()
+	return latency
+}
+
+// Run runs the function
+func (f *FunctionWithLatencyProfile) Run(ctx context.Context, w io.Writer) {
+	latency, bucket := f.draw()
+	f.sleep(ctx, w, latency, Record{Timeout: latency, Outcome: bucket, Note: bucket})
+}
+
+// Buckets returns how many runs drew a latency in each percentile bucket
+func (f *FunctionWithLatencyProfile) Buckets() map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buckets := make(map[string]int, len(f.buckets))
+	for bucket, n := range f.buckets {
+		buckets[bucket] = n
+	}
+	return buckets
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithLatencyProfile) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithLatencyProfile) String() string {
+	return f.name
+}
+
+// Describe returns the percentiles of the function
+func (f *FunctionWithLatencyProfile) Describe() string {
+	return fmt.Sprintf("p50 %dms, p95 %dms, p99 %dms", f.p50, f.p95, f.p99)
+}
@@ -0,0 +1,61 @@
+package t0simulator
+
+import (
+	"fmt"
+	"strings"
+)
+
+var markdownEscaper = strings.NewReplacer("|", `\|`)
+
+// Markdown returns the report as a Markdown table followed by a bold summary line
+func (r *Result) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "SIMULATOR:%s\n\n", markdownEscaper.Replace(r.Name))
+	b.WriteString("| Name | Max Timeout(ms) | Remaining(ms) | Status |\n")
+	b.WriteString("| --- | ---: | ---: | --- |\n")
+	fmt.Fprintf(&b, "| Init | %s | %s | |\n", ms(r.Budget), ms(r.Budget))
+	for _, rec := range r.Records {
+		if !rec.Executed {
+			continue
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", markdownEscaper.Replace(rec.Name), ms(rec.Timeout), ms(rec.Remaining), rec.Status())
+	}
+	b.WriteString("\n")
+	if r.TimedOut || r.Cancelled || r.Aborted || r.Abandoned {
+		for _, name := range r.Interrupted() {
+			fmt.Fprintf(&b, "**Interrupted:** %s (started, not finished)\n\n", markdownEscaper.Replace(name))
+		}
+		outcome := "Time out reached"
+		switch {
+		case r.Abandoned:
+			outcome = guardNote(r)
+		case r.Aborted:
+			outcome = "Aborted after a panic"
+		case !r.TimedOut:
+			outcome = "Cancelled by caller"
+		}
+		if len(r.NeverStarted()) == 0 {
+			fmt.Fprintf(&b, "**%s**\n", outcome)
+		} else {
+			fmt.Fprintf(&b, "**%s, never started:**\n\n", outcome)
+		}
+		for _, name := range r.NeverStarted() {
+			fmt.Fprintf(&b, "- %s\n", markdownEscaper.Replace(name))
+		}
+	} else {
+		fmt.Fprintf(&b, "**Done with time left %s ms**\n", ms(r.Remaining))
+	}
+	if r.Overdraft > 0 {
+		fmt.Fprintf(&b, "\n%s\n", overdraftNote(r, UnitMillisecond))
+	}
+	if r.Normalized > 0 {
+		fmt.Fprintf(&b, "\n%s\n", normalizedNote(r.Normalized))
+	}
+	if r.Reserved > 0 {
+		fmt.Fprintf(&b, "\n%s\n", reservedNote(r, UnitMillisecond))
+	}
+	if r.Paused > 0 {
+		fmt.Fprintf(&b, "\n%s\n", pausedNote(r, UnitMillisecond))
+	}
+	return b.String()
+}
@@ -0,0 +1,30 @@
+package t0simulator
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	s := NewSimulator("a|b", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("c|d").WithTimeout(30),
+		NewFunction("e").WithTimeout(50),
+		NewFunction("f|g").WithTimeout(40),
+		NewFunction("h").WithTimeout(10),
+	)
+	res, _ := s.Run()
+	want := "SIMULATOR:a\\|b\n" +
+		"\n" +
+		"| Name | Max Timeout(ms) | Remaining(ms) | Status |\n" +
+		"| --- | ---: | ---: | --- |\n" +
+		"| Init | 100 | 100 | |\n" +
+		"| c\\|d | 30 | 70 | ok |\n" +
+		"| e | 50 | 20 | ok |\n" +
+		"\n" +
+		"**Interrupted:** f\\|g (started, not finished)\n" +
+		"\n" +
+		"**Time out reached, never started:**\n" +
+		"\n" +
+		"- h\n"
+	if got := res.Markdown(); got != want {
+		t.Errorf("markdown\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,41 @@
+package t0simulator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// mermaidEscaper strips the characters Mermaid uses as task syntax
+var mermaidEscaper = strings.NewReplacer(":", " ", ";", " ", "#", " ")
+
+// Mermaid returns the budget timeline of the run as a Mermaid gantt diagram.
+// Executed processes are drawn from their start to their end offset, the deadline
+// is drawn as a milestone and unexecuted processes as zero-length tasks after it.
+func (r *Result) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "    title SIMULATOR %s\n", mermaidEscaper.Replace(r.Name))
+	b.WriteString("    dateFormat x\n")
+	b.WriteString("    axisFormat %Q\n")
+	b.WriteString("    section Processes\n")
+	for i, rec := range r.Records {
+		if !rec.Executed {
+			continue
+		}
+		fmt.Fprintf(&b, "    %s :p%d, %s, %s\n", mermaidEscaper.Replace(rec.Name), i, ms(rec.Start), ms(rec.End))
+	}
+	fmt.Fprintf(&b, "    Deadline :milestone, deadline, %s, %s\n", ms(r.Budget), ms(r.Budget))
+
+	unexecuted := false
+	for i, rec := range r.Records {
+		if rec.Executed {
+			continue
+		}
+		if !unexecuted {
+			b.WriteString("    section Unexecuted\n")
+			unexecuted = true
+		}
+		fmt.Fprintf(&b, "    %s :crit, p%d, after deadline, 0ms\n", mermaidEscaper.Replace(rec.Name), i)
+	}
+	return b.String()
+}
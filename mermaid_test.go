@@ -0,0 +1,27 @@
+package t0simulator
+
+import "testing"
+
+func TestMermaidUnexecutedMarkers(t *testing.T) {
+	s := NewSimulator("gantt", 50, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewFunction("b").WithTimeout(30),
+		NewFunction("c").WithTimeout(10),
+	)
+	res, _ := s.Run()
+	want := `gantt
+    title SIMULATOR gantt
+    dateFormat x
+    axisFormat %Q
+    section Processes
+    a :p0, 0, 30
+    Deadline :milestone, deadline, 50, 50
+    section Unexecuted
+    b :crit, p1, after deadline, 0ms
+    c :crit, p2, after deadline, 0ms
+`
+	if got := res.Mermaid(); got != want {
+		t.Errorf("diagram\n%s\nwant\n%s", got, want)
+	}
+}
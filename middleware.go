@@ -0,0 +1,67 @@
+package t0simulator
+
+import (
+	"context"
+	"io"
+)
+
+// Middleware runs around every process of a simulator, the members of groups included.
+// It runs the process by calling next.Run, with ctx or a context derived from it, or
+// it returns without calling it to skip the process, which is then reported skipped by
+// middleware. The time a middleware spends around next, e.g. a network hop, is left
+// out of the row of the process and consumes the budget of the run.
+type Middleware func(ctx context.Context, next Proccess, w io.Writer)
+
+// Use appends mw to the middlewares of the simulator, they apply in the order they are
+// added, the first one being the outermost
+func (s *Simulator) Use(mw Middleware) {
+	s.running.Lock()
+	defer s.running.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middleware = append(s.middleware, mw)
+}
+
+type middlewareKey struct{}
+
+// withMiddleware returns a copy of ctx running the processes through mws, nil for none
+func withMiddleware(ctx context.Context, mws []Middleware) context.Context {
+	return context.WithValue(ctx, middlewareKey{}, mws)
+}
+
+// middlewareOf returns the middlewares of ctx
+func middlewareOf(ctx context.Context) []Middleware {
+	mws, _ := ctx.Value(middlewareKey{}).([]Middleware)
+	return mws
+}
+
+// nextProcess is the process handed to a middleware, running it runs the next
+// middleware or the process itself
+type nextProcess struct {
+	Proccess
+	mws    []Middleware
+	called bool
+}
+
+func (n *nextProcess) Run(ctx context.Context, w io.Writer) {
+	n.called = true
+	if len(n.mws) == 0 {
+		n.Proccess.Run(ctx, w)
+		return
+	}
+	through(ctx, w, n.Proccess, n.mws)
+}
+
+// through runs p through mws, p is recorded skipped when one of them does not run it
+func through(ctx context.Context, w io.Writer, p Proccess, mws []Middleware) {
+	n := &nextProcess{Proccess: p, mws: mws[1:]}
+	mws[0](ctx, n, w)
+	if !n.called {
+		bypass(ctx, w, p)
+	}
+}
+
+// bypass records p skipped by a middleware
+func bypass(ctx context.Context, w io.Writer, p Proccess) {
+	skipProcess(ctx, w, p, "skipped by middleware")
+}
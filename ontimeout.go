@@ -0,0 +1,44 @@
+package t0simulator
+
+import "io"
+
+// TimeoutHandler is implemented by processes recording compensating work when the run
+// ends before they finish, e.g. the rollback of a transaction. OnTimeout is called
+// once the deadline is reached or the run is cancelled, on the interrupted processes
+// and the ones that never started, with the remaining budget in ms. Rows written to w
+// are printed before the footer of the report.
+type TimeoutHandler interface {
+	OnTimeout(remaining int64, w io.Writer)
+}
+
+// WithOnTimeout returns a copy of the function calling fn when the run ends before it
+// finishes, see TimeoutHandler. It is set before picking the kind of function:
+//
+//	t0simulator.NewFunction("Save to DB").WithOnTimeout(rollback).WithTimeout(300)
+func (f Function) WithOnTimeout(fn func(remaining int64, w io.Writer)) Function {
+	f.onTimeout = fn
+	return f
+}
+
+// OnTimeout calls the function set by WithOnTimeout, if any
+func (f *Function) OnTimeout(remaining int64, w io.Writer) {
+	if f.onTimeout != nil {
+		f.onTimeout(remaining, w)
+	}
+}
+
+// notifyTimeout calls OnTimeout on the processes of ps that have not been executed
+// and on their children, in the order of the report
+func notifyTimeout(ps []Proccess, remaining int64, w io.Writer) {
+	for _, p := range ps {
+		if p.IsExecuted() {
+			continue
+		}
+		if h, ok := p.(TimeoutHandler); ok {
+			h.OnTimeout(remaining, w)
+		}
+		if c, ok := p.(Composite); ok {
+			notifyTimeout(c.Children(), remaining, w)
+		}
+	}
+}
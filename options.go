@@ -0,0 +1,279 @@
+package t0simulator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Option denotes a function that configures a Simulator
+type Option func(*Simulator) error
+
+// WithOutput sets the writer the report is written to, os.Stdout is used by default
+func WithOutput(w io.Writer) Option {
+	return func(s *Simulator) error {
+		if w != nil {
+			s.output = w
+		}
+		return nil
+	}
+}
+
+// Verbosity denotes how much the simulator prints
+type Verbosity int
+
+const (
+	// Normal prints the report, it is the default verbosity
+	Normal Verbosity = iota
+	// Quiet prints nothing, the outcome is only available from the returned Result
+	Quiet
+	// Verbose additionally prints the start, the end and the deadline of every function
+	Verbose
+)
+
+// WithVerbosity sets the verbosity of the simulator
+func WithVerbosity(v Verbosity) Option {
+	return func(s *Simulator) error {
+		s.verbosity = v
+		return nil
+	}
+}
+
+// Execution denotes how the registered processes of a simulator are run
+type Execution int
+
+const (
+	// Sequential runs the processes one after another, it is the default execution
+	Sequential Execution = iota
+	// Parallel runs the processes concurrently against the same deadline, the run
+	// finishes when the slowest is done or the deadline is reached
+	Parallel
+)
+
+// WithExecution sets how the registered processes are run
+func WithExecution(e Execution) Option {
+	return func(s *Simulator) error {
+		s.execution = e
+		return nil
+	}
+}
+
+// WithColor enables ANSI colors when enabled is true and the output is a terminal.
+// Rows are yellow when the remaining budget drops below the color threshold and
+// red when it is exhausted. Colors are disabled by default.
+func WithColor(enabled bool) Option {
+	return func(s *Simulator) error {
+		s.color = enabled
+		return nil
+	}
+}
+
+// WithColorThreshold sets the percentage of the budget under which rows are yellow, default is 20
+func WithColorThreshold(percent float64) Option {
+	return func(s *Simulator) error {
+		s.colorThreshold = percent
+		return nil
+	}
+}
+
+// WithTimestamps appends the wall-clock time at which each function finished to the report rows
+func WithTimestamps() Option {
+	return func(s *Simulator) error {
+		s.timestamps = true
+		return nil
+	}
+}
+
+// WithDrift appends to the report rows the time each process actually ran for,
+// measured with the monotonic clock, and its drift from the declared timeout, to tell
+// when scheduling noise eats into the budget
+func WithDrift() Option {
+	return func(s *Simulator) error {
+		s.drift = true
+		return nil
+	}
+}
+
+// WithAbandonBackground makes Run abandon the background processes still running once
+// the registered processes are done, instead of waiting for them. Abandoned processes
+// are reported as unexecuted.
+func WithAbandonBackground() Option {
+	return func(s *Simulator) error {
+		s.abandonBackground = true
+		return nil
+	}
+}
+
+// WithRandSeed seeds every registered process consuming randomness from seed, so the
+// series of runs of the simulator is reproduced draw for draw by the same seed. The
+// processes are seeded at the first run, and again after processes are registered.
+// Processes run without a seed draw from the default source of math/rand.
+func WithRandSeed(seed int64) Option {
+	return func(s *Simulator) error {
+		s.seeding = &seeding{seed: seed}
+		return nil
+	}
+}
+
+// WithClock runs the simulation on c instead of the real clock, e.g. a FakeClock
+// advanced by a test. It takes precedence over WithVirtualClock.
+func WithClock(c Clock) Option {
+	return func(s *Simulator) error {
+		if c == nil {
+			return errors.New("t0simulator: nil clock")
+		}
+		s.clock = c
+		return nil
+	}
+}
+
+// WithVirtualClock runs the simulation on a virtual clock: simulated functions consume
+// no real time, the clock jumps to the end of the next one instead, so a run finishes
+// instantly with the same report as on the wall clock. The clock only advances once every
+// process is blocked on it: processes doing real work, such as HTTP processes, take no
+// time on it, and must not wait for the deadline of the run.
+func WithVirtualClock() Option {
+	return func(s *Simulator) error {
+		s.virtualClock = true
+		return nil
+	}
+}
+
+// WithAbortOnPanic stops the run once a process panicked, the next processes are
+// reported never started. By default the run goes on with the next process.
+func WithAbortOnPanic() Option {
+	return func(s *Simulator) error {
+		s.abortOnPanic = true
+		return nil
+	}
+}
+
+// WithMaxWallTime abandons a run still in progress after d of wall-clock time, even if
+// its budget has not expired, e.g. when a process blocks forever. The processes in
+// progress are left running and reported stuck, and the error returned by Run matches
+// ErrWallTimeGuard rather than ErrBudgetExceeded. There is no limit by default.
+func WithMaxWallTime(d time.Duration) Option {
+	return func(s *Simulator) error {
+		if d < 0 {
+			return fmt.Errorf("t0simulator: negative max wall time %s", d)
+		}
+		s.maxWallTime = d
+		return nil
+	}
+}
+
+// WithChainWeight sets the share of the budget left the simulator is allotted as a
+// stage of Chain, in (0,1]
+func WithChainWeight(weight float64) Option {
+	return func(s *Simulator) error {
+		if !(weight > 0 && weight <= 1) {
+			return fmt.Errorf("t0simulator: chain weight %v out of range (0,1]", weight)
+		}
+		s.chainWeight = weight
+		return nil
+	}
+}
+
+// WithWarmupRuns makes RunN run the simulator k times before the series, to let
+// cold-start functions warm up, and leave those runs out of the statistics
+func WithWarmupRuns(k int) Option {
+	return func(s *Simulator) error {
+		if k < 0 {
+			return fmt.Errorf("t0simulator: negative warm-up runs %d", k)
+		}
+		s.warmup = k
+		return nil
+	}
+}
+
+// WithReservedBudget keeps ms of the budget back for the work done once the processes
+// are done, such as writing the response: the processes run against a deadline ms
+// earlier than the budget, and the report tells how much of the reservation is left.
+func WithReservedBudget(ms int) Option {
+	return func(s *Simulator) error {
+		if ms < 0 {
+			return fmt.Errorf("t0simulator: negative reserved budget %d", ms)
+		}
+		s.reserved = time.Duration(ms) * time.Millisecond
+		return nil
+	}
+}
+
+// WithTimeScale runs the simulation factor times faster than the wall clock: every sleep
+// and the deadline of the run are factor times shorter in real time, while the report
+// keeps the original scale. The scheduling overhead is scaled too, so it grows with the
+// factor in the report. It is ignored with WithClock or WithVirtualClock.
+func WithTimeScale(factor float64) Option {
+	return func(s *Simulator) error {
+		if !(factor > 0) || math.IsInf(factor, 1) {
+			return fmt.Errorf("t0simulator: time scale %v not a positive factor", factor)
+		}
+		s.timeScale = factor
+		return nil
+	}
+}
+
+// WithPriorityThreshold sets the allotment in ms under which a priority dynamic context
+// function is allotted the whole remaining budget instead of its share, 30 by default.
+// It returns an error when ms is negative, zero disables the escalation.
+func WithPriorityThreshold(ms int) Option {
+	return func(s *Simulator) error {
+		if ms < 0 {
+			return fmt.Errorf("t0simulator: negative priority threshold %d", ms)
+		}
+		s.priorityThreshold = time.Duration(ms) * time.Millisecond
+		return nil
+	}
+}
+
+// WithRowFormatter sets the formatter of the table report, replacing the default tabwriter table
+func WithRowFormatter(f RowFormatter) Option {
+	return func(s *Simulator) error {
+		s.formatter = f
+		return nil
+	}
+}
+
+// WithLogger sets a logger receiving a record per executed process and a final
+// record with the outcome of the run, nothing is logged by default
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Simulator) error {
+		s.logger = logger
+		return nil
+	}
+}
+
+// WithProgress sets a writer receiving a progress line every time a function is done
+// and when the deadline is reached
+func WithProgress(w io.Writer) Option {
+	return func(s *Simulator) error {
+		s.progress = w
+		return nil
+	}
+}
+
+// WithFormat sets the output format of the report
+func WithFormat(f Format) Option {
+	return func(s *Simulator) error {
+		s.format = f
+		return nil
+	}
+}
+
+// WithTabWriter configures the tabwriter of the table report, the defaults are a
+// minwidth of 0, a padding of 1, a space padchar and debug separators enabled
+func WithTabWriter(minwidth, padding int, padchar byte, debug bool) Option {
+	return func(s *Simulator) error {
+		if minwidth < 0 {
+			return fmt.Errorf("t0simulator: negative tabwriter minwidth %d", minwidth)
+		}
+		if padding < 0 {
+			return fmt.Errorf("t0simulator: negative tabwriter padding %d", padding)
+		}
+		s.tab = tabConfig{minwidth: minwidth, padding: padding, padchar: padchar, debug: debug}
+		return nil
+	}
+}
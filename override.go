@@ -0,0 +1,57 @@
+package t0simulator
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeoutOverrider is implemented by the processes with a fixed timeout, overrideTimeout
+// returns a copy of the process running for timeout instead
+type timeoutOverrider interface {
+	overrideTimeout(timeout time.Duration) Proccess
+}
+
+// OverrideTimeout returns a copy of p running for ms instead of its declared timeout, so
+// a single definition can be registered in scenarios with different latencies. The copy
+// has the configuration of p and a fresh state, as if it never ran, processes depending
+// on p should depend on the copy instead. It returns an error matching ErrNoFixedTimeout when p has
+// no fixed timeout, e.g. a dynamic context function.
+func OverrideTimeout(p Proccess, ms int) (Proccess, error) {
+	if isNil(p) {
+		return nil, ErrNilProcess
+	}
+	if ms < 0 {
+		return nil, fmt.Errorf("t0simulator: process %q: negative timeout override %d", p.String(), ms)
+	}
+	o, ok := p.(timeoutOverrider)
+	if !ok {
+		return nil, fmt.Errorf("%w: process %q (%T)", ErrNoFixedTimeout, p.String(), p)
+	}
+	return o.overrideTimeout(time.Duration(ms) * time.Millisecond), nil
+}
+
+// AddFunctionWithTimeout appends p to the processes registered with its timeout
+// overridden to ms, see OverrideTimeout and AddFunctions
+func (s *Simulator) AddFunctionWithTimeout(p Proccess, ms int) error {
+	o, err := OverrideTimeout(p, ms)
+	if err != nil {
+		return err
+	}
+	return s.AddFunctions(o)
+}
+
+func (f *FunctionWithTimeout) overrideTimeout(timeout time.Duration) Proccess {
+	// the copy draws from its own source, set by WithRandSeed or WithSource
+	return &FunctionWithTimeout{
+		Function:         f.configuration(),
+		timeout:          timeout,
+		jitter:           f.jitter,
+		failure:          f.failure,
+		skipIfOverBudget: f.skipIfOverBudget,
+		coldStart:        f.coldStart,
+	}
+}
+
+func (d *Delay) overrideTimeout(timeout time.Duration) Proccess {
+	return &Delay{Function: d.configuration(), delay: int(timeout / time.Millisecond)}
+}
@@ -0,0 +1,36 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverrideTimeoutCopiesConfiguration(t *testing.T) {
+	dep := NewFunction("dep").WithTimeout(5)
+	f := NewFunction("f").WithTimeout(10)
+	f.DependsOn(dep)
+	f.DependsOn(dep)
+	f.DependsOn(dep)
+	s := NewSimulator("override", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(dep, f)
+	if _, err := s.Run(); err != nil || !f.IsExecuted() {
+		t.Fatalf("run returned %v, executed %v", err, f.IsExecuted())
+	}
+	p, err := OverrideTimeout(f, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := p.(*FunctionWithTimeout)
+	if o.IsExecuted() || o.Succeeded() {
+		t.Errorf("copy executed %v, succeeded %v, want a fresh state", o.IsExecuted(), o.Succeeded())
+	}
+	other := NewFunction("other").WithTimeout(1)
+	o.DependsOn(other)
+	f.DependsOn(dep)
+	if deps := o.Dependencies(); len(deps) != 4 || deps[3] != Proccess(other) {
+		t.Errorf("dependencies of the copy %v, want other last", deps)
+	}
+	if o.timeout != 20*time.Millisecond {
+		t.Errorf("copy timeout %v, want 20ms", o.timeout)
+	}
+}
@@ -0,0 +1,61 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// recoverProcess recovers a panic of p, it records the row of p failed with the panic and
+// the run goes on with the next process. sp measures the run of p.
+func recoverProcess(ctx context.Context, w io.Writer, p Proccess, sp span) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	err := &PanicError{Process: p.String(), Value: v, Stack: debug.Stack()}
+	if f, ok := p.(interface{ function() *Function }); ok {
+		f.function().isExecuted.set(true)
+		f.function().succeeded.set(false)
+	}
+	if r, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+		r.panic(p)
+	}
+	sp.end(ctx, w, p.String(), Record{Timeout: since(ctx, sp.startedAt), Failed: true, Err: err, Note: fmt.Sprintf("PANIC: %v", v)})
+}
+
+// panic marks p as panicked, so it is not reported unexecuted
+func (r *recorder) panic(p Proccess) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.panicked == nil {
+		r.panicked = make(map[Proccess]bool)
+	}
+	r.panicked[p] = true
+}
+
+// hasPanicked returns true if a process panicked during the run
+func (r *recorder) hasPanicked() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.panicked) > 0
+}
+
+// isPanicked returns true if p panicked during the run
+func (r *recorder) isPanicked(p Proccess) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.panicked[p]
+}
+
+// Panics returns the panics of the processes during the run
+func (r *Result) Panics() []*PanicError {
+	var panics []*PanicError
+	for _, rec := range r.Records {
+		if err, ok := rec.Err.(*PanicError); ok {
+			panics = append(panics, err)
+		}
+	}
+	return panics
+}
@@ -0,0 +1,63 @@
+package t0simulator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+// panicking is a process whose Run panics with its name
+type panicking string
+
+func (p panicking) Run(context.Context, io.Writer) {
+	panic(string(p))
+}
+
+func (p panicking) IsExecuted() bool {
+	return false
+}
+
+func (p panicking) String() string {
+	return string(p)
+}
+
+func TestPanicRecovered(t *testing.T) {
+	tests := map[string]struct {
+		opts       []Option
+		state      State
+		unexecuted []string
+	}{
+		"run goes on":    {state: StateDone},
+		"abort on panic": {opts: []Option{WithAbortOnPanic()}, state: StateNotStarted, unexecuted: []string{"b"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewSimulator("panic", 100, append([]Option{WithVirtualClock(), WithVerbosity(Quiet)}, tt.opts...)...)
+			s.RegisterFunctions(
+				NewFunction("a").WithTimeout(10),
+				panicking("boom"),
+				NewFunction("b").WithTimeout(10),
+			)
+			res, err := s.Run()
+			var perr *PanicError
+			if !errors.As(err, &perr) || perr.Process != "boom" || perr.Value != "boom" {
+				t.Fatalf("error %v, want the panic of boom", err)
+			}
+			if got := res.Unexecuted(); !slices.Equal(got, tt.unexecuted) {
+				t.Errorf("unexecuted %v, want %v", got, tt.unexecuted)
+			}
+			boom := res.ByName("boom")
+			if len(boom) != 1 || !boom[0].Failed || boom[0].Note != "PANIC: boom" {
+				t.Fatalf("records of boom %+v, want a single failed one", boom)
+			}
+			if a := res.ByName("a"); len(a) != 1 || a[0].State() != StateDone {
+				t.Errorf("records of a %+v, want a single done one", a)
+			}
+			if b := res.ByName("b"); len(b) != 1 || b[0].State() != tt.state {
+				t.Errorf("records of b %+v, want a single one %s", b, tt.state)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package t0simulator
+
+import (
+	"context"
+	"slices"
+)
+
+// position denotes the position of the process running under a context in the tree of
+// the registered processes, children are the processes it runs, one after another when
+// sequential, and pending the processes that run after it
+type position struct {
+	path       []int
+	children   []Proccess
+	sequential bool
+	pending    []Proccess
+}
+
+// sequence is implemented by the composite processes running their children one after
+// another
+type sequence interface {
+	sequential()
+}
+
+type positionKey struct{}
+
+// withChildren returns a copy of ctx running ps, the registered processes of a run, one
+// after another when sequential
+func withChildren(ctx context.Context, ps []Proccess, sequential bool) context.Context {
+	return context.WithValue(ctx, positionKey{}, position{children: ps, sequential: sequential})
+}
+
+// withSequence returns a copy of ctx whose children run one after another when
+// sequential is true, concurrently otherwise
+func withSequence(ctx context.Context, sequential bool) context.Context {
+	pos := positionOf(ctx)
+	pos.sequential = sequential
+	return context.WithValue(ctx, positionKey{}, pos)
+}
+
+// positionOf returns the position of the process running under ctx
+func positionOf(ctx context.Context) position {
+	pos, _ := ctx.Value(positionKey{}).(position)
+	return pos
+}
+
+// enter returns a copy of ctx running p, p is placed among the children of the process
+// running under ctx. A process that is not one of them, e.g. the process wrapped by a
+// conditional or a background process, shares the position of its wrapper.
+func enter(ctx context.Context, p Proccess) context.Context {
+	pos := positionOf(ctx)
+	path, pending := pos.path, pos.pending
+	if i := slices.Index(pos.children, p); i >= 0 {
+		path = append(slices.Clip(path), i)
+		if pos.sequential {
+			pending = append(slices.Clone(pos.children[i+1:]), pending...)
+		}
+	}
+	var children []Proccess
+	if c, ok := p.(Composite); ok {
+		children = c.Children()
+	}
+	_, sequential := p.(sequence)
+	return context.WithValue(ctx, positionKey{}, position{path: path, children: children, sequential: sequential, pending: pending})
+}
+
+// Index returns the index of the process among the children of its parent, or among
+// the registered processes for a registered one, -1 when the position is unknown
+func (r Record) Index() int {
+	if len(r.Path) == 0 {
+		return -1
+	}
+	return r.Path[len(r.Path)-1]
+}
+
+// Parent returns the path of the parent of the process, nil for a registered process
+func (r Record) Parent() []int {
+	if len(r.Path) < 2 {
+		return nil
+	}
+	return r.Path[:len(r.Path)-1]
+}
+
+// ByName returns the records of the processes named name, in the order of Records
+func (r *Result) ByName(name string) []Record {
+	var records []Record
+	for _, rec := range r.Records {
+		if rec.Name == name {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// sortRecords sorts records in the order the processes are registered, whatever the
+// order they ran in. A composite process is listed after its children when it finished,
+// before them otherwise, and the records of a same process keep the order they were
+// recorded in, e.g. the iterations of a repeat.
+func sortRecords(records []Record) {
+	slices.SortStableFunc(records, func(a, b Record) int {
+		for i := 0; i < len(a.Path) && i < len(b.Path); i++ {
+			if a.Path[i] != b.Path[i] {
+				return a.Path[i] - b.Path[i]
+			}
+		}
+		switch {
+		case len(a.Path) < len(b.Path):
+			if a.Executed {
+				return 1
+			}
+			return -1
+		case len(a.Path) > len(b.Path):
+			if b.Executed {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+}
@@ -0,0 +1,31 @@
+package t0simulator
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestRecordsInRegistrationOrder(t *testing.T) {
+	s := NewSimulator("order", 100, WithVirtualClock(), WithVerbosity(Quiet), WithExecution(Parallel))
+	s.RegisterFunctions(
+		NewFunction("slow").WithTimeout(50),
+		NewParallelGroup("g", NewFunction("fast").WithTimeout(10), NewFunction("slow").WithTimeout(30)),
+		NewFunction("fast").WithTimeout(5),
+	)
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, rec := range res.Records {
+		got = append(got, fmt.Sprintf("%s%v", rec.Name, rec.Path))
+	}
+	if want := []string{"slow[0]", "fast[1 0]", "slow[1 1]", "g[1]", "fast[2]"}; !slices.Equal(got, want) {
+		t.Errorf("records %v, want %v", got, want)
+	}
+	slow := res.ByName("slow")
+	if len(slow) != 2 || slow[0].Index() != 0 || slow[1].Index() != 1 || !slices.Equal(slow[1].Parent(), []int{1}) {
+		t.Errorf("records named slow %v", slow)
+	}
+}
@@ -0,0 +1,87 @@
+package t0simulator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pauser holds the processes of a run between two of them while the simulator is paused
+type pauser struct {
+	mu sync.Mutex
+	// running is the number of runs in progress
+	running int
+	// resume is closed on Resume, it is nil when the simulator is not paused
+	resume chan struct{}
+}
+
+// holder is implemented by the clocks that can be stopped while a run is paused
+type holder interface {
+	hold()
+	release()
+}
+
+// Pause pauses the runs in progress: the process in progress finishes, then the run
+// blocks until Resume is called. On the virtual clock the budget clock is stopped meanwhile,
+// on the wall clock the paused time is consumed from the budget. Pausing takes effect
+// between the processes of a sequential run only. It is safe to call from another
+// goroutine, and does nothing when no run is in progress.
+func (s *Simulator) Pause() {
+	s.pause.mu.Lock()
+	defer s.pause.mu.Unlock()
+	if s.pause.running > 0 && s.pause.resume == nil {
+		s.pause.resume = make(chan struct{})
+	}
+}
+
+// Resume resumes the run paused by Pause, it does nothing when the run is not paused
+func (s *Simulator) Resume() {
+	s.pause.mu.Lock()
+	defer s.pause.mu.Unlock()
+	if s.pause.resume != nil {
+		close(s.pause.resume)
+		s.pause.resume = nil
+	}
+}
+
+// start makes Pause take effect until the run stops
+func (p *pauser) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running++
+}
+
+// stop resumes the runs if they are paused and makes the following Pause calls no-ops
+// once the last run stopped
+func (p *pauser) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	if p.running == 0 && p.resume != nil {
+		close(p.resume)
+		p.resume = nil
+	}
+}
+
+// wait blocks while the simulator is paused, until it is resumed or ctx is done. It
+// returns the wall-clock time paused and the time that elapsed meanwhile on the clock
+// of ctx, which was consumed from the budget.
+func (p *pauser) wait(ctx context.Context) (paused, consumed time.Duration) {
+	p.mu.Lock()
+	resume := p.resume
+	p.mu.Unlock()
+	if resume == nil {
+		return 0, 0
+	}
+	if h, ok := clockOf(ctx).(holder); ok {
+		h.hold()
+		defer h.release()
+	}
+	// the pause is measured on the wall clock, the clock of the run may be stopped
+	started, clockStarted := time.Now(), now(ctx)
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	}
+	return time.Since(started), since(ctx, clockStarted)
+}
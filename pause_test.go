@@ -0,0 +1,48 @@
+package t0simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// pausing returns a process pausing s and resuming it after d of wall-clock time
+func pausing(s *Simulator, d time.Duration) Proccess {
+	return NewRealProcess("pause", func(context.Context) error {
+		s.Pause()
+		time.AfterFunc(d, s.Resume)
+		return nil
+	})
+}
+
+func TestPauseStopsVirtualClock(t *testing.T) {
+	s := NewSimulator("paused", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	// pausing before the run does nothing
+	s.Pause()
+	s.RegisterFunctions(pausing(s, 20*time.Millisecond), NewFunction("a").WithTimeout(30))
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Paused < 20*time.Millisecond || res.PausedConsumed != 0 {
+		t.Errorf("paused %v consuming %v, want at least 20ms consuming nothing", res.Paused, res.PausedConsumed)
+	}
+	if a := res.ByName("a"); len(a) != 1 || a[0].Start != 0 || a[0].Remaining != 70*time.Millisecond {
+		t.Errorf("records of a %+v, want one starting at once with 70ms left", a)
+	}
+}
+
+func TestPauseConsumesWallClock(t *testing.T) {
+	s := NewSimulator("paused", 100, WithVerbosity(Quiet))
+	s.RegisterFunctions(pausing(s, 30*time.Millisecond), NewFunction("a").WithTimeout(30))
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Paused < 30*time.Millisecond || res.PausedConsumed < 30*time.Millisecond {
+		t.Errorf("paused %v consuming %v, want at least 30ms consumed", res.Paused, res.PausedConsumed)
+	}
+	if a := res.ByName("a"); len(a) != 1 || a[0].Start < 30*time.Millisecond || a[0].Remaining > 40*time.Millisecond {
+		t.Errorf("records of a %+v, want one starting after the pause", a)
+	}
+}
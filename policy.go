@@ -0,0 +1,76 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeadlinePolicy denotes what happens to the processes running when the budget expires
+type DeadlinePolicy int
+
+const (
+	// HardCancel interrupts the processes running at the deadline, it is the default policy
+	HardCancel DeadlinePolicy = iota
+	// FinishCurrent lets the processes running at the deadline finish, like a service
+	// that stops launching new work without cancelling the work in flight, and starts
+	// no process after it. The time they ran past the deadline is the overdraft of the run.
+	FinishCurrent
+)
+
+// WithDeadlinePolicy sets what happens to the processes running when the budget
+// expires, HardCancel by default. The deadline of a parent context, see RunContext,
+// still interrupts them.
+func WithDeadlinePolicy(p DeadlinePolicy) Option {
+	return func(s *Simulator) error {
+		s.deadlinePolicy = p
+		return nil
+	}
+}
+
+type softDeadlineKey struct{}
+
+// withSoftDeadline returns a copy of ctx whose deadline is d away, the deadline is not
+// enforced: processes see the time left before it, and do not start once it passed
+func withSoftDeadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	deadline := now(ctx).Add(d)
+	if parent, ok := ctx.Deadline(); ok && parent.Before(deadline) {
+		deadline = parent
+	}
+	inner, cancel := context.WithCancel(context.WithValue(ctx, softDeadlineKey{}, true))
+	return &clockContext{Context: inner, deadline: deadline}, cancel
+}
+
+// overdue returns true when the soft deadline of ctx passed, see withSoftDeadline
+func overdue(ctx context.Context) bool {
+	soft, _ := ctx.Value(softDeadlineKey{}).(bool)
+	return soft && getRemaining(ctx) <= 0
+}
+
+// stopped returns true when no process should start against ctx anymore
+func stopped(ctx context.Context) bool {
+	return ctx.Err() != nil || overdue(ctx)
+}
+
+// allExecuted returns true if all of ps have been executed
+func allExecuted(ps []Proccess) bool {
+	for _, p := range ps {
+		if !p.IsExecuted() {
+			return false
+		}
+	}
+	return true
+}
+
+// overdraftNote returns the line of the report telling how long the run went past the deadline
+func overdraftNote(res *Result, u Unit) string {
+	return fmt.Sprintf("Overdraft %s %s, the processes running at the deadline were finished", u.format(res.Overdraft), u)
+}
+
+// deadline returns a copy of ctx whose deadline is d away, enforced according to p
+func (p DeadlinePolicy) deadline(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if p == FinishCurrent {
+		return withSoftDeadline(ctx, d)
+	}
+	return withTimeout(ctx, d)
+}
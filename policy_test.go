@@ -0,0 +1,47 @@
+package t0simulator
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeadlinePolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy    DeadlinePolicy
+		b         State
+		overdraft time.Duration
+		note      bool
+	}{
+		"hard cancel":    {policy: HardCancel, b: StateInterrupted},
+		"finish current": {policy: FinishCurrent, b: StateDone, overdraft: 20 * time.Millisecond, note: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			s := NewSimulator("policy", 100, WithVirtualClock(), WithOutput(&out), WithDeadlinePolicy(tt.policy))
+			s.RegisterFunctions(
+				NewFunction("a").WithTimeout(60),
+				NewFunction("b").WithTimeout(60),
+				NewFunction("c").WithTimeout(10),
+			)
+			res, err := s.Run()
+			if !errors.Is(err, ErrBudgetExceeded) || !res.TimedOut {
+				t.Fatalf("error %v, timed out %v, want the budget exceeded", err, res.TimedOut)
+			}
+			for name, want := range map[string]State{"a": StateDone, "b": tt.b, "c": StateNotStarted} {
+				if recs := res.ByName(name); len(recs) != 1 || recs[0].State() != want {
+					t.Errorf("records of %s %+v, want a single one %s", name, recs, want)
+				}
+			}
+			if res.Overdraft != tt.overdraft {
+				t.Errorf("overdraft %v, want %v", res.Overdraft, tt.overdraft)
+			}
+			if got := strings.Contains(out.String(), "Overdraft 20 ms, the processes running at the deadline were finished"); got != tt.note {
+				t.Errorf("overdraft noted %v, want %v in\n%s", got, tt.note, out.String())
+			}
+		})
+	}
+}
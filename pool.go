@@ -0,0 +1,152 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Pool denotes a pool of connections shared between processes, e.g. a database pool
+type Pool struct {
+	slots *semaphore
+}
+
+// NewPool returns a pool of size connections, it panics when size is lower than 1
+func NewPool(size int) *Pool {
+	if size < 1 {
+		panic(fmt.Sprintf("t0simulator: pool: size %d lower than 1", size))
+	}
+	return &Pool{slots: newSemaphore(size)}
+}
+
+// acquire waits for a free connection, it returns false when ctx expires first
+func (p *Pool) acquire(ctx context.Context) bool {
+	return p.slots.acquire(ctx)
+}
+
+// release frees a connection
+func (p *Pool) release() {
+	p.slots.release()
+}
+
+// Size returns the number of connections of the pool
+func (p *Pool) Size() int {
+	return p.slots.limit
+}
+
+// InUse returns the number of connections in use
+func (p *Pool) InUse() int {
+	return p.slots.inUse()
+}
+
+// FunctionWithPool denotes a function simulation needing a connection of a pool
+type FunctionWithPool struct {
+	Function
+	pool           *Pool
+	serviceLatency int
+}
+
+// WithPool returns a simulated function acquiring a connection of pool, queuing while
+// none is free, then sleeping serviceLatency ms before releasing it. It panics when
+// pool is nil.
+func (f Function) WithPool(pool *Pool, serviceLatency int) *FunctionWithPool {
+	if pool == nil {
+		panic(fmt.Sprintf("t0simulator: function %q: nil pool", f.name))
+	}
+	return &FunctionWithPool{
+		Function:       f,
+		pool:           pool,
+		serviceLatency: serviceLatency,
+	}
+}
+
+// Run runs the function, the time waited for a connection is charged against the
+// budget and reported in the Wait column. Nothing is recorded when ctx expires first.
+func (f *FunctionWithPool) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	service := time.Duration(f.serviceLatency) * time.Millisecond
+	done := f.track(ctx, sp, 0)
+	if !f.pool.acquire(ctx) {
+		return
+	}
+	wait := since(ctx, sp.startedAt)
+	ok := pause(ctx, service)
+	f.pool.release()
+	if !ok {
+		return
+	}
+	done()
+	f.finish(ctx, w, sp, Record{Timeout: service, Wait: wait})
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithPool) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithPool) String() string {
+	return f.name
+}
+
+// Describe returns the pool size and the service latency of the function
+func (f *FunctionWithPool) Describe() string {
+	return fmt.Sprintf("pool of %d, service %dms", f.pool.Size(), f.serviceLatency)
+}
+
+// semaphore limits the processes holding it at once, its waiters are blocked on the
+// clock of the run
+type semaphore struct {
+	mu    sync.Mutex
+	held  int
+	limit int
+	// released is closed and replaced every time the semaphore is released
+	released chan struct{}
+}
+
+// newSemaphore returns a semaphore held by limit processes at most
+func newSemaphore(limit int) *semaphore {
+	return &semaphore{limit: limit, released: make(chan struct{})}
+}
+
+// acquire waits for the semaphore, it returns false when ctx expires first
+func (s *semaphore) acquire(ctx context.Context) bool {
+	for {
+		s.mu.Lock()
+		if s.held < s.limit {
+			s.held++
+			s.mu.Unlock()
+			return true
+		}
+		released := s.released
+		s.mu.Unlock()
+		expired := false
+		await(ctx, func() bool { return closed(released) || ctx.Err() != nil }, func() {
+			select {
+			case <-released:
+			case <-ctx.Done():
+				expired = true
+			}
+		})
+		if expired {
+			return false
+		}
+	}
+}
+
+// release releases the semaphore, waking its waiters up
+func (s *semaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.held--
+	close(s.released)
+	s.released = make(chan struct{})
+}
+
+// inUse returns the number of processes holding the semaphore
+func (s *semaphore) inUse() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.held
+}
@@ -0,0 +1,22 @@
+package t0simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPoolWaitNotedInDefaultReport(t *testing.T) {
+	var out bytes.Buffer
+	pool := NewPool(1)
+	s := NewSimulator("pool", 100, WithVirtualClock(), WithOutput(&out))
+	s.RegisterFunctions(NewParallelGroup("g",
+		NewFunction("a").WithPool(pool, 30),
+		NewFunction("b").WithPool(pool, 30),
+	))
+	s.Run()
+	// the member served second waited for the connection of the first one
+	if !strings.Contains(out.String(), "|30              |40            |ok     |waited 30 ms    |\n") {
+		t.Errorf("report misses the time waited for a connection\n%s", out.String())
+	}
+}
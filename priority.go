@@ -0,0 +1,95 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Priority denotes the priority level of a dynamic context function, it decides which
+// functions get escalated or demoted when the budget runs short. Levels are ordered,
+// any integer is a valid level.
+type Priority int
+
+const (
+	// PriorityBestEffort functions pay for the higher ones registered after them: their
+	// allotment is shrunk so the threshold is left to each of those, and they are skipped
+	// when less than the threshold is left to them
+	PriorityBestEffort Priority = -1
+	// PriorityNormal functions are allotted their share, it is the default priority
+	PriorityNormal Priority = 0
+	// PriorityHigh functions are allotted the whole remaining budget when their share is
+	// under the threshold, less the threshold for each higher function registered after them
+	PriorityHigh Priority = 1
+	// PriorityCritical functions are escalated like high ones, and before them
+	PriorityCritical Priority = 2
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityBestEffort:
+		return "best-effort"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+	return strconv.Itoa(int(p))
+}
+
+// WithDynamicContextPriority returns a simulated function run with dynamic context
+// timeout like WithDynamicContext, with a priority level instead of a protected flag.
+// The priorities of the functions registered after it are taken into account in a
+// sequential run, see Priority. It panics when weight is not in (0,1].
+func (f Function) WithDynamicContextPriority(weight float64, priority Priority) *FunctionWithDynamiContext {
+	d, err := f.WithDynamicContextPriorityE(weight, priority)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// WithDynamicContextPriorityE is like WithDynamicContextPriority, it returns an error
+// when weight is not in (0,1]
+func (f Function) WithDynamicContextPriorityE(weight float64, priority Priority) (*FunctionWithDynamiContext, error) {
+	if !validWeight(weight) {
+		return nil, fmt.Errorf("t0simulator: function %q: weight %v out of range (0,1]", f.name, weight)
+	}
+	return &FunctionWithDynamiContext{
+		Function: f,
+		weight:   weight,
+		priority: priority,
+	}, nil
+}
+
+// priorityOf maps the protected flag of WithDynamicContext to a priority
+func priorityOf(isPriority bool) Priority {
+	if isPriority {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// pendingOf returns the processes that run after the one running under ctx: its later
+// siblings in the groups running their children one after another, up to the registered
+// processes of a sequential run, see enter
+func pendingOf(ctx context.Context) []Proccess {
+	return positionOf(ctx).pending
+}
+
+// priorityNote returns the note of the row of a dynamic context function
+func priorityNote(p Priority, escalated, demoted bool) []string {
+	var notes []string
+	if p != PriorityNormal {
+		notes = append(notes, "priority "+p.String())
+	}
+	switch {
+	case escalated:
+		notes = append(notes, "escalated")
+	case demoted:
+		notes = append(notes, "demoted")
+	}
+	return notes
+}
@@ -0,0 +1,42 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriorityDemotionInGroup(t *testing.T) {
+	want := map[string]time.Duration{"a": 70 * time.Millisecond, "b": 30 * time.Millisecond}
+	a := func() Proccess { return NewFunction("a").WithDynamicContextPriority(0.9, PriorityBestEffort) }
+	b := func() Proccess { return NewFunction("b").WithDynamicContextPriority(0.5, PriorityCritical) }
+	for name, ps := range map[string][]Proccess{
+		"registered": {a(), b()},
+		"group":      {NewSequentialGroup("g", a(), b())},
+		"nested":     {NewSequentialGroup("g", a()), NewSequentialGroup("h", b())},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewSimulator("priority", 100, WithVirtualClock(), WithVerbosity(Quiet))
+			s.RegisterFunctions(ps...)
+			for _, step := range s.Estimate().Steps {
+				if d, ok := want[step.Name]; ok && step.Estimated != d {
+					t.Errorf("%s: estimated %v, want %v", step.Name, step.Estimated, d)
+				}
+			}
+			res, err := s.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, rec := range res.Records {
+				if d, ok := want[rec.Name]; ok && rec.Timeout != d {
+					t.Errorf("%s: allotted %v, want %v", rec.Name, rec.Timeout, d)
+				}
+			}
+			if rec := res.ByName("a"); len(rec) != 1 || !rec[0].Demoted {
+				t.Errorf("a not demoted")
+			}
+			if rec := res.ByName("b"); len(rec) != 1 || !rec[0].Escalated {
+				t.Errorf("b not escalated")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// FunctionWithQueue denotes a function simulation waiting in a queue before being served,
+// e.g. an async worker
+type FunctionWithQueue struct {
+	Function
+	wait      LatencySampler
+	serviceMs int
+}
+
+// WithQueue returns a simulated function waiting a duration drawn from wait, then
+// sleeping serviceMs ms. It panics when wait is nil or serviceMs is negative.
+func (f Function) WithQueue(wait LatencySampler, serviceMs int) *FunctionWithQueue {
+	if wait == nil {
+		panic(fmt.Sprintf("t0simulator: function %q: nil queue wait sampler", f.name))
+	}
+	if serviceMs < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative service time %d", f.name, serviceMs))
+	}
+	return &FunctionWithQueue{
+		Function:  f,
+		wait:      wait,
+		serviceMs: serviceMs,
+	}
+}
+
+// Run runs the function, the row breaks the time out into the queue wait and the service time
+func (f *FunctionWithQueue) Run(ctx context.Context, w io.Writer) {
+	wait := f.wait.Sample()
+	service := time.Duration(f.serviceMs) * time.Millisecond
+	f.sleep(ctx, w, wait+service, Record{
+		Timeout: wait + service,
+		Wait:    wait,
+		Service: service,
+		Note:    fmt.Sprintf("wait %dms, service %dms", wait.Milliseconds(), service.Milliseconds()),
+	})
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithQueue) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithQueue) String() string {
+	return f.name
+}
+
+// Describe returns the service time of the function
+func (f *FunctionWithQueue) Describe() string {
+	if d, ok := f.wait.(Describer); ok {
+		return fmt.Sprintf("queue %s, service %dms", d.Describe(), f.serviceMs)
+	}
+	return fmt.Sprintf("queue, service %dms", f.serviceMs)
+}
@@ -0,0 +1,36 @@
+package t0simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueueWaitNotedOnce(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("queue", 100, WithVirtualClock(), WithOutput(&out))
+	s.RegisterFunctions(NewFunction("a").WithQueue(FixedLatency(20), 30))
+	s.Run()
+	if !strings.Contains(out.String(), "a    |50              |50            |ok     |wait 20ms, service 30ms |\n") {
+		t.Errorf("report misses the queue row noting the wait once\n%s", out.String())
+	}
+}
+
+func TestQueueWaitAndService(t *testing.T) {
+	s := NewSimulator("queue", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithQueue(FixedLatency(20), 30),
+		NewFunction("b").WithQueue(FixedLatency(40), 20),
+	)
+	res, _ := s.Run()
+	a := res.ByName("a")
+	if len(a) != 1 || !a[0].Executed || a[0].Wait != 20*time.Millisecond || a[0].Service != 30*time.Millisecond || a[0].Consumed != 50*time.Millisecond {
+		t.Errorf("records of a %+v, want one waiting 20ms served in 30ms", a)
+	}
+	// b is still waiting in the queue at the deadline
+	b := res.ByName("b")
+	if len(b) != 1 || !b[0].InProgress || b[0].Consumed != 50*time.Millisecond || b[0].Timeout != 60*time.Millisecond {
+		t.Errorf("records of b %+v, want one interrupted after 50ms of 60ms", b)
+	}
+}
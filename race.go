@@ -0,0 +1,113 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Racing denotes a process running two processes concurrently and completing with the
+// first of them to finish
+type Racing struct {
+	a, b       Proccess
+	winner     Proccess
+	wasted     time.Duration
+	isExecuted flag
+}
+
+// Race returns a process running a and b concurrently under the same context, the
+// loser is cancelled as soon as the winner finishes
+func Race(a, b Proccess) *Racing {
+	return &Racing{a: a, b: b}
+}
+
+// Run runs both processes and waits until one of them finishes or ctx expires. Only the
+// rows of the winner are kept, the row of the race reports the winner and the budget
+// the loser consumed before it was cancelled.
+func (r *Racing) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	raceCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type branch struct {
+		p    Proccess
+		held *heldRecords
+	}
+	// the first branch to finish wins, finished is closed once it is set
+	var won branch
+	var once sync.Once
+	finished := make(chan struct{})
+	for _, p := range []Proccess{r.a, r.b} {
+		heldCtx, held := hold(nested(raceCtx))
+		spawn(ctx, func() {
+			runProcess(heldCtx, held.writer(), p)
+			once.Do(func() {
+				won = branch{p: p, held: held}
+				close(finished)
+			})
+		})
+	}
+
+	await(ctx, func() bool { return closed(finished) || ctx.Err() != nil }, func() {
+		select {
+		case <-finished:
+		case <-ctx.Done():
+		}
+	})
+	if ctx.Err() != nil {
+		return
+	}
+	cancel()
+	wasted := since(ctx, sp.startedAt)
+	won.held.release(w)
+
+	loser := r.b
+	if won.p == r.b {
+		loser = r.a
+	}
+	r.winner, r.wasted = won.p, wasted
+	r.isExecuted.set(true)
+	sp.end(ctx, w, r.String(), Record{
+		Timeout: wasted,
+		Failed:  !succeeded(won.p),
+		Outcome: won.p.String(),
+		Note:    fmt.Sprintf("won by %s, %s wasted %dms", won.p, loser, wasted.Milliseconds()),
+	})
+}
+
+// Winner returns the process that won the last run, nil before the first one
+func (r *Racing) Winner() Proccess {
+	return r.winner
+}
+
+// Wasted returns the budget the loser of the last run consumed before it was cancelled
+func (r *Racing) Wasted() time.Duration {
+	return r.wasted
+}
+
+// IsExecuted returns true if one of the processes has finished
+func (r *Racing) IsExecuted() bool {
+	return r.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (r *Racing) ResetRun() {
+	r.isExecuted.set(false)
+	r.winner, r.wasted = nil, 0
+}
+
+func (r *Racing) String() string {
+	return r.a.String() + " vs " + r.b.String()
+}
+
+// Describe returns the processes racing
+func (r *Racing) Describe() string {
+	return "race"
+}
+
+// Children returns both processes
+func (r *Racing) Children() []Proccess {
+	return []Proccess{r.a, r.b}
+}
@@ -0,0 +1,59 @@
+package t0simulator
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// random is a goroutine safe source of randomness, it falls back to the default
+// source of math/rand until a source is set
+type random struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (r *random) setSource(src rand.Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.r = rand.New(src)
+}
+
+// float64 returns a number in [0.0,1.0)
+func (r *random) float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.r == nil {
+		return rand.Float64()
+	}
+	return r.r.Float64()
+}
+
+// int63n returns a number in [0,n)
+func (r *random) int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.r == nil {
+		return rand.Int63n(n)
+	}
+	return r.r.Int63n(n)
+}
+
+// normFloat64 returns a number drawn from the standard normal distribution
+func (r *random) normFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.r == nil {
+		return rand.NormFloat64()
+	}
+	return r.r.NormFloat64()
+}
+
+// expFloat64 returns a number drawn from the exponential distribution of rate 1
+func (r *random) expFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.r == nil {
+		return rand.ExpFloat64()
+	}
+	return r.r.ExpFloat64()
+}
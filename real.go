@@ -0,0 +1,71 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// RealProcess denotes a real call run through the simulator, e.g. a client call
+type RealProcess struct {
+	Function
+	fn  func(ctx context.Context) error
+	err error
+}
+
+// NewRealProcess returns a process calling fn with the context of the run
+func NewRealProcess(name string, fn func(ctx context.Context) error) *RealProcess {
+	return &RealProcess{
+		Function: NewFunction(name),
+		fn:       fn,
+	}
+}
+
+// Run calls fn and records the time it took, the row is failed when fn returns an
+// error or panics
+func (p *RealProcess) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	done := p.track(ctx, sp, 0)
+	p.err = p.call(ctx)
+	done()
+	rec := Record{Timeout: since(ctx, sp.startedAt), Failed: p.err != nil, Err: p.err}
+	if p.err != nil {
+		rec.Note = p.err.Error()
+	}
+	p.finish(ctx, w, sp, rec)
+}
+
+// call calls fn, recovering a panic as an error
+func (p *RealProcess) call(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("t0simulator: process %q panicked: %v", p.name, r)
+		}
+	}()
+	return p.fn(ctx)
+}
+
+// Err returns the error of the last run
+func (p *RealProcess) Err() error {
+	return p.err
+}
+
+// IsExecuted returns true if the call has returned
+func (p *RealProcess) IsExecuted() bool {
+	return p.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (p *RealProcess) ResetRun() {
+	p.Function.ResetRun()
+	p.err = nil
+}
+
+func (p *RealProcess) String() string {
+	return p.name
+}
+
+// Describe tells the process is a real call
+func (p *RealProcess) Describe() string {
+	return "real call"
+}
@@ -0,0 +1,94 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Unbounded is the number of iterations of a Repeat running until the budget is exhausted
+const Unbounded = 0
+
+// Repeat denotes a process running its child several times in a row, e.g. a pagination
+// or a polling loop
+type Repeat struct {
+	p          Proccess
+	n          int
+	iterations int
+	isExecuted flag
+}
+
+// NewRepeat returns a process running p up to n times, or until the budget is exhausted
+// when n is Unbounded. It panics when n is negative.
+func NewRepeat(p Proccess, n int) *Repeat {
+	if n < 0 {
+		panic(fmt.Sprintf("t0simulator: repeat %q: negative iterations %d", p.String(), n))
+	}
+	return &Repeat{p: p, n: n}
+}
+
+// Run runs the iterations, it stops early when the remaining budget is lower than
+// the duration of the previous iteration. An unbounded repeat also stops after an iteration
+// that took no time, it would never exhaust the budget. Nothing is recorded when ctx
+// expires first.
+func (r *Repeat) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	child := nested(ctx)
+	r.iterations = 0
+	var last time.Duration
+	for r.n == Unbounded || r.iterations < r.n {
+		if r.iterations > 0 && (getRemaining(ctx) < last || r.n == Unbounded && last <= 0) {
+			break
+		}
+		started := now(ctx)
+		runProcess(child, w, r.p)
+		last = since(ctx, started)
+		if stopped(ctx) {
+			return
+		}
+		r.iterations++
+	}
+
+	rec := Record{Timeout: since(ctx, sp.startedAt), Attempts: r.iterations}
+	if r.n == Unbounded {
+		rec.Note = fmt.Sprintf("%d iterations", r.iterations)
+	} else {
+		rec.Note = fmt.Sprintf("iterations %d/%d", r.iterations, r.n)
+	}
+	r.isExecuted.set(true)
+	sp.end(ctx, w, r.String(), rec)
+}
+
+// Iterations returns the number of iterations completed by the last run
+func (r *Repeat) Iterations() int {
+	return r.iterations
+}
+
+// IsExecuted returns true if the iterations have finished before the deadline
+func (r *Repeat) IsExecuted() bool {
+	return r.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (r *Repeat) ResetRun() {
+	r.isExecuted.set(false)
+	r.iterations = 0
+}
+
+func (r *Repeat) String() string {
+	return r.p.String()
+}
+
+// Describe returns the number of iterations
+func (r *Repeat) Describe() string {
+	if r.n == Unbounded {
+		return "repeat until the budget is exhausted"
+	}
+	return fmt.Sprintf("repeat %d times", r.n)
+}
+
+// Children returns the repeated process
+func (r *Repeat) Children() []Proccess {
+	return []Proccess{r.p}
+}
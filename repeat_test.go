@@ -0,0 +1,40 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRepeat(t *testing.T) {
+	for name, tt := range map[string]struct {
+		p          Proccess
+		n          int
+		iterations int
+		consumed   time.Duration
+		note       string
+	}{
+		"n times":             {NewFunction("a").WithTimeout(20), 3, 3, 60 * time.Millisecond, "iterations 3/3"},
+		"early stop":          {NewFunction("a").WithTimeout(30), 5, 3, 90 * time.Millisecond, "iterations 3/5"},
+		"unbounded":           {NewFunction("a").WithTimeout(30), Unbounded, 3, 90 * time.Millisecond, "3 iterations"},
+		"unbounded zero time": {NewFunction("a").WithTimeout(0), Unbounded, 1, 0, "1 iterations"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := NewRepeat(tt.p, tt.n)
+			s := NewSimulator("repeat", 100, WithVirtualClock(), WithVerbosity(Quiet))
+			s.RegisterFunctions(r)
+			res, err := s.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Iterations() != tt.iterations {
+				t.Errorf("%d iterations, want %d", r.Iterations(), tt.iterations)
+			}
+			if a := res.ByName("a"); len(a) != tt.iterations+1 {
+				t.Errorf("%d records of a, want one per iteration and the repeat", len(a))
+			}
+			if rep := res.Records[len(res.Records)-1]; rep.Depth != 0 || rep.Consumed != tt.consumed || rep.Note != tt.note {
+				t.Errorf("repeat consumed %v noted %q, want %v noted %q", rep.Consumed, rep.Note, tt.consumed, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package t0simulator
+
+// Resettable is implemented by processes keeping the state of their last run, e.g.
+// whether they have been executed. ResetRun clears it so they run again as if they
+// never ran, the state meant to persist across runs, such as the state of a circuit
+// breaker or the warm state of a function, is kept.
+type Resettable interface {
+	ResetRun()
+}
+
+// CounterResettable is implemented by processes keeping counters across runs, such as
+// the consecutive failures of a circuit breaker. ResetCounters clears them, the warm
+// state of the process is kept.
+type CounterResettable interface {
+	ResetCounters()
+}
+
+// ResetRun clears the state of the last run of the function
+func (f *Function) ResetRun() {
+	f.isExecuted.set(false)
+	f.succeeded.set(false)
+}
+
+// Reset clears the state of the last run of the registered processes and of their
+// children, so the simulator can run again. Run calls it before running them, and
+// it waits for the current run to finish. A function with a cold start stays warm, see
+// WithColdStart.
+func (s *Simulator) Reset() {
+	s.running.Lock()
+	defer s.running.Unlock()
+	s.reset()
+}
+
+// reset resets the processes of c, the running lock of their simulator must be held
+func (c *config) reset() {
+	for _, p := range c.process {
+		resetProcess(p)
+	}
+}
+
+// resetCounters clears the counters of p and of its children
+func resetCounters(p Proccess) {
+	if r, ok := p.(CounterResettable); ok {
+		r.ResetCounters()
+	}
+	if c, ok := p.(Composite); ok {
+		for _, child := range c.Children() {
+			resetCounters(child)
+		}
+	}
+}
+
+// resetProcess clears the state of the last run of p and of its children
+func resetProcess(p Proccess) {
+	if r, ok := p.(Resettable); ok {
+		r.ResetRun()
+	}
+	if c, ok := p.(Composite); ok {
+		for _, child := range c.Children() {
+			resetProcess(child)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package t0simulator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRunTwice(t *testing.T) {
+	s := NewSimulator("twice", 50, WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewFunction("b").WithTimeout(30),
+		NewFunction("c").WithTimeout(10),
+	)
+	for run := 1; run <= 2; run++ {
+		res, _ := s.Run()
+		if !res.TimedOut {
+			t.Fatalf("run %d did not time out", run)
+		}
+		if got, want := res.Unexecuted(), []string{"b", "c"}; !slices.Equal(got, want) {
+			t.Errorf("run %d: unexecuted %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestColdStartResets(t *testing.T) {
+	f := NewFunction("f").WithTimeout(10).WithColdStart(20)
+	s := NewSimulator("cold", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(f)
+	outcome := func(s *Simulator) string {
+		t.Helper()
+		res, err := s.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.ByName("f")[0].Outcome
+	}
+	if got := outcome(s); got != StartCold {
+		t.Errorf("first run %s, want %s", got, StartCold)
+	}
+	if got := outcome(s); got != StartWarm {
+		t.Errorf("second run %s, want %s", got, StartWarm)
+	}
+	s.Reset()
+	if got := outcome(s); got != StartWarm {
+		t.Errorf("run after Simulator.Reset %s, want %s", got, StartWarm)
+	}
+	if got := outcome(s.Clone("copy")); got != StartCold {
+		t.Errorf("run of a clone %s, want %s", got, StartCold)
+	}
+	f.Reset()
+	if got := outcome(s); got != StartCold {
+		t.Errorf("run after Reset of the function %s, want %s", got, StartCold)
+	}
+}
@@ -0,0 +1,548 @@
+package t0simulator
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Record denotes the outcome of a single simulated process
+type Record struct {
+	Name string
+	// Timeout is the declared timeout, or the allotted sub-timeout for dynamic context functions
+	Timeout time.Duration
+	// Weight is the weight of dynamic context functions, zero otherwise
+	Weight float64
+	// Available is the budget remaining when the process started
+	Available time.Duration
+	Consumed  time.Duration
+	Remaining time.Duration
+	Executed  bool
+	// Escalated is true when a priority dynamic context function was allotted the
+	// whole remaining budget because its share was under the threshold
+	Escalated bool
+	// Priority is the priority of dynamic context functions, and Demoted is true when
+	// their allotment was shrunk for the higher ones registered after them
+	Priority Priority
+	Demoted  bool
+	// Wait is the time the process waited before starting, e.g. for a rate limiter token
+	Wait time.Duration
+	// Service is the time spent serving a queued process once its wait is over
+	Service time.Duration
+	// Cleanup is the cancellation cost consumed after the deadline interrupted the process
+	Cleanup time.Duration
+	// Clamped is true when the timeout computed for the process was clamped
+	Clamped bool
+	// Planned is the slice of the budget planned upfront for a dynamic context
+	// function, see ProportionalAllocation
+	Planned time.Duration
+	// Attempts is the number of attempts made by retrying functions
+	Attempts int
+	// Failed is true when the process consumed its time without succeeding
+	Failed bool
+	// Err is the error returned by a real process
+	Err error
+	// StatusCode is the status code of the response of an HTTP process
+	StatusCode int
+	// InProgress is true when the deadline interrupted the process, Consumed is then
+	// the time it ran for and Timeout the time it planned to run for, zero when unknown
+	InProgress bool
+	// Started is true for a process started but not finished that is not InProgress,
+	// e.g. a composite process some children of which had started when the deadline
+	// interrupted it, or an abandoned background process
+	Started bool
+	// Delivered and Chunks are the chunks delivered by a streaming process out of the
+	// chunks of its response, an interrupted stream is partial when some were delivered
+	Delivered int
+	Chunks    int
+	// Skipped is true when a process was reached before the deadline but did not run,
+	// e.g. a conditional process or a function without enough budget left. The row is
+	// recorded as executed.
+	Skipped bool
+	// Violation tells why an assertion on the remaining budget failed, empty otherwise
+	Violation string
+	// Overhead is true for processes simulating an overhead rather than a call to a dependency
+	Overhead bool
+	// Outcome is the sampled outcome of probabilistic functions, e.g. a cache hit or
+	// miss, or the percentile bucket of a latency profile
+	Outcome string
+	// Depth is the nesting level of the process, zero for registered processes and
+	// one more than the composite process otherwise
+	Depth int
+	// Path is the position of the process in the tree of the registered processes, the
+	// index of the registered process followed by the index of the process among the
+	// children of every composite process down to it, e.g. [2 0] for the first child
+	// of the third registered process
+	Path []int
+	// Note is a short annotation printed at the end of the row
+	Note string
+	// Start and End are the offsets from the start of the run, measured with the
+	// monotonic clock. Both are negative when the process has not been executed.
+	Start time.Duration
+	End   time.Duration
+	// DeadlineAt is the offset of the deadline the process ran against
+	DeadlineAt time.Duration
+	// FinishedAt is the wall-clock time at which the process finished
+	FinishedAt time.Time
+	// Elapsed is the time the process actually ran for, measured with the monotonic
+	// clock, while Timeout is the time it declared. It is zero for unexecuted processes.
+	Elapsed time.Duration
+
+	startedAt time.Time
+	deadline  time.Time
+}
+
+// Status denotes the outcome of a single process
+type Status string
+
+const (
+	// StatusOK is the status of a process executed successfully
+	StatusOK Status = "ok"
+	// StatusFailed is the status of a process that consumed its time without succeeding
+	StatusFailed Status = "failed"
+	// StatusSkipped is the status of a process reached before the deadline but skipped
+	StatusSkipped Status = "skipped"
+	// StatusInProgress is the status of a process interrupted by the deadline
+	StatusInProgress Status = "in progress"
+	// StatusUnexecuted is the status of a process not started before the deadline
+	StatusUnexecuted Status = "unexecuted"
+)
+
+// Status returns the status of the process
+func (r Record) Status() Status {
+	switch {
+	case r.InProgress:
+		return StatusInProgress
+	case !r.Executed:
+		return StatusUnexecuted
+	case r.Skipped:
+		return StatusSkipped
+	case r.Failed:
+		return StatusFailed
+	}
+	return StatusOK
+}
+
+// Drift returns the time the process overshot its declared timeout by, negative when
+// it undershot it, e.g. when interrupted. It is zero for unexecuted and skipped processes.
+func (r Record) Drift() time.Duration {
+	if r.Skipped || !r.Executed && !r.InProgress {
+		return 0
+	}
+	return r.Elapsed - r.Timeout
+}
+
+// State denotes how far a process got before the end of the run
+type State string
+
+const (
+	// StateDone is the state of a process that finished, skipped ones included
+	StateDone State = "done"
+	// StateInterrupted is the state of a process started but not finished
+	StateInterrupted State = "interrupted"
+	// StateNotStarted is the state of a process that never started
+	StateNotStarted State = "not started"
+)
+
+// State returns how far the process got
+func (r Record) State() State {
+	switch {
+	case r.Executed:
+		return StateDone
+	case r.InProgress || r.Started:
+		return StateInterrupted
+	}
+	return StateNotStarted
+}
+
+// Partial returns true if the process is an interrupted stream that delivered some of its chunks
+func (r Record) Partial() bool {
+	return r.InProgress && r.Delivered > 0
+}
+
+// Result denotes the outcome of a simulator run
+type Result struct {
+	Name   string
+	Budget time.Duration
+	// StartedAt is the wall-clock time at which the run started
+	StartedAt time.Time
+	Records   []Record
+	TimedOut  bool
+	// Aborted is true when the run stopped after a process panicked, see WithAbortOnPanic
+	Aborted bool
+	// Abandoned is true when the run was abandoned by the wall-time guard set to
+	// MaxWallTime, Stuck holds the names of the processes that were in progress
+	Abandoned   bool
+	MaxWallTime time.Duration
+	Stuck       []string
+	// Cancelled is true when the parent context of the run was cancelled before the deadline
+	Cancelled bool
+	Remaining time.Duration
+	// Reserved is the part of the budget kept back from the processes, Budget is the
+	// working budget they ran against, without it
+	Reserved time.Duration
+	// Overdraft is the time the processes ran past the deadline, see FinishCurrent
+	Overdraft time.Duration
+	// Normalized is the sum of the weights of the dynamic context functions when it was
+	// over 1 and ProportionalAllocation normalized them, zero otherwise
+	Normalized float64
+	// Deadline is the absolute deadline the budget was derived from, zero for a relative budget
+	Deadline time.Time
+	// Execution tells whether the processes were run one after another or concurrently
+	Execution Execution
+	// Seed is the seed the processes were drawn from and SeedRun the position of the
+	// run in the series it started, 1 for the first one. SeedRun is 0 when the
+	// processes were not seeded.
+	Seed    int64
+	SeedRun int
+	// Paused is the wall-clock time the run was paused, and PausedConsumed the part of
+	// it consumed from the budget, zero when the budget clock was stopped
+	Paused         time.Duration
+	PausedConsumed time.Duration
+	Summary        Summary
+}
+
+// Summary denotes the budget usage of a run
+type Summary struct {
+	// Consumed is the total consumed from the budget
+	Consumed time.Duration
+	// Utilization is the percentage of the budget consumed, 100 or more when exceeded
+	Utilization float64
+	// LargestConsumer is the name of the executed process that consumed the most
+	LargestConsumer string
+	LargestConsumed time.Duration
+	Executed        int
+	// Failed is the number of executed processes that failed
+	Failed int
+	// Skipped is the number of processes skipped, they are not counted as executed
+	Skipped int
+	// Makespan is the time from the start of the run to the end of the last registered
+	// process, CriticalPath is the name of that process
+	Makespan     time.Duration
+	CriticalPath string
+	// Overhead is the time consumed by overhead processes, at any depth, and Dependencies
+	// the time consumed by the other executed processes
+	Overhead     time.Duration
+	Dependencies time.Duration
+	// Violations lists the assertions that failed as "name: reason"
+	Violations []string
+	// Partial is the number of interrupted streaming processes that delivered some of their chunks
+	Partial    int
+	Registered int
+}
+
+// summarize computes the summary of the result from its records
+func (r *Result) summarize() {
+	sum := Summary{
+		Consumed: r.Budget - r.Remaining,
+	}
+	if r.Budget > 0 {
+		sum.Utilization = float64(sum.Consumed) / float64(r.Budget) * 100
+	}
+	var consumed time.Duration
+	for _, rec := range r.Records {
+		if rec.Overhead && rec.Executed {
+			sum.Overhead += rec.Consumed
+		}
+		if rec.Violation != "" {
+			sum.Violations = append(sum.Violations, rec.Name+": "+rec.Violation)
+		}
+		if rec.Depth > 0 {
+			continue
+		}
+		sum.Registered++
+		if rec.Partial() {
+			sum.Partial++
+		}
+		if !rec.Executed {
+			continue
+		}
+		if rec.Skipped {
+			sum.Skipped++
+			continue
+		}
+		sum.Executed++
+		consumed += rec.Consumed
+		if rec.End >= sum.Makespan {
+			sum.Makespan, sum.CriticalPath = rec.End, rec.Name
+		}
+		if rec.Failed {
+			sum.Failed++
+		}
+		if sum.LargestConsumer == "" || rec.Consumed > sum.LargestConsumed {
+			sum.LargestConsumer = rec.Name
+			sum.LargestConsumed = rec.Consumed
+		}
+	}
+	sum.Dependencies = consumed - sum.Overhead
+	r.Summary = sum
+}
+
+// Interrupted returns the names of the processes started but not finished
+func (r *Result) Interrupted() []string {
+	return r.names(StateInterrupted)
+}
+
+// NeverStarted returns the names of the processes that never started
+func (r *Result) NeverStarted() []string {
+	return r.names(StateNotStarted)
+}
+
+// names returns the names of the processes in state s
+func (r *Result) names(s State) []string {
+	var names []string
+	for _, rec := range r.Records {
+		if rec.State() == s {
+			names = append(names, rec.Name)
+		}
+	}
+	return names
+}
+
+// Unexecuted returns the names of processes that have not been executed, including
+// the unfinished members of composite processes, interrupted and never started alike
+func (r *Result) Unexecuted() []string {
+	var names []string
+	for _, rec := range r.Records {
+		if !rec.Executed {
+			names = append(names, rec.Name)
+		}
+	}
+	return names
+}
+
+type recorderKey struct{}
+
+type depthKey struct{}
+
+// nested returns a child of ctx for the processes of a composite process
+func nested(ctx context.Context) context.Context {
+	return context.WithValue(ctx, depthKey{}, depth(ctx)+1)
+}
+
+// depth returns the nesting level of the processes run under ctx
+func depth(ctx context.Context) int {
+	d, _ := ctx.Value(depthKey{}).(int)
+	return d
+}
+
+// recorder collects the records emitted by processes during a run
+type recorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	formatter RowFormatter
+	logger    *slog.Logger
+	records   []Record
+	closed    bool
+	// cleanups counts the cleanups of interrupted functions the run waits for, unless settled
+	cleanups waitGroup
+	settled  bool
+	// active holds the functions running against the deadline of the run
+	active map[*Function]activeRun
+	// panicked holds the processes that panicked
+	panicked map[Proccess]bool
+	// skipped holds the processes skipped by a middleware or for a dependency not met
+	skipped map[Proccess]bool
+	// parent is the recorder of the run the records are held from, see hold, the
+	// functions running are tracked there
+	parent *recorder
+}
+
+// activeRun denotes a function running against the deadline of a run
+type activeRun struct {
+	startedAt time.Time
+	available time.Duration
+	planned   time.Duration
+}
+
+func newRecorder(start time.Time, formatter RowFormatter, logger *slog.Logger) *recorder {
+	return &recorder{start: start, formatter: formatter, logger: logger, active: make(map[*Function]activeRun)}
+}
+
+// begin marks f as running since sp started for the planned duration
+func (r *recorder) begin(f *Function, sp span, planned time.Duration) {
+	if r.parent != nil {
+		r.parent.begin(f, sp, planned)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active[f] = activeRun{startedAt: sp.startedAt, available: sp.before, planned: planned}
+}
+
+// end marks f as no longer running
+func (r *recorder) end(f *Function) {
+	if r.parent != nil {
+		r.parent.end(f)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.active, f)
+}
+
+// unexecuted returns the records of the processes of ps that have not been executed
+// at, along with the unexecuted children of composite ones, with their declared timeout
+// and weight. Functions still running are recorded in progress. path is the path of the parent of ps, and note annotates
+// the record of the i-th process, it may be nil.
+func (r *recorder) unexecuted(ps []Proccess, path []int, depth int, note func(i int) string, at time.Time) []Record {
+	var records []Record
+	for i, p := range ps {
+		if p.IsExecuted() || r.isPanicked(p) || r.isSkipped(p) {
+			continue
+		}
+		rec := Record{Name: p.String(), Depth: depth, Path: append(slices.Clip(path), i), Start: -1, End: -1}
+		switch p := p.(type) {
+		case *FunctionWithTimeout:
+			rec.Timeout = p.timeout
+		case *Delay:
+			rec.Timeout = time.Duration(p.delay) * time.Millisecond
+		case *FunctionWithDynamiContext:
+			rec.Weight, rec.Priority = p.weight, p.priority
+		}
+		if f, ok := p.(interface{ function() *Function }); ok {
+			r.mu.Lock()
+			run, running := r.active[f.function()]
+			r.mu.Unlock()
+			if running {
+				rec.InProgress = true
+				rec.Timeout, rec.Available = run.planned, run.available
+				rec.Consumed = at.Sub(run.startedAt)
+				rec.Elapsed = rec.Consumed
+				rec.Start = run.startedAt.Sub(r.start)
+				if s, ok := p.(interface{ progress() (int, int) }); ok {
+					rec.Delivered, rec.Chunks = s.progress()
+				}
+			}
+		}
+		if note != nil {
+			rec.Note = note(i)
+		}
+		var children []Record
+		if c, ok := p.(Composite); ok {
+			var childNote func(int) string
+			if n, ok := p.(interface{ unexecutedNote(int) string }); ok {
+				childNote = n.unexecutedNote
+			}
+			children = r.unexecuted(c.Children(), rec.Path, depth+1, childNote, at)
+			rec.Started = started(c.Children(), children)
+		}
+		records = append(append(records, rec), children...)
+	}
+	return records
+}
+
+// started returns true if any of children was executed or interrupted, records are
+// their unexecuted records
+func started(children []Proccess, records []Record) bool {
+	for _, c := range children {
+		if c.IsExecuted() {
+			return true
+		}
+	}
+	for _, rec := range records {
+		if rec.State() == StateInterrupted {
+			return true
+		}
+	}
+	return false
+}
+
+// add stores rec and logs it when a logger is configured, records added after
+// the recorder is closed are dropped
+func (r *recorder) add(rec Record) Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec.Start = rec.startedAt.Sub(r.start)
+	rec.End = rec.FinishedAt.Sub(r.start)
+	rec.DeadlineAt = rec.deadline.Sub(r.start)
+	if r.closed {
+		return rec
+	}
+	r.records = append(r.records, rec)
+	if r.logger != nil {
+		r.logger.Info("process done",
+			slog.String("process", rec.Name),
+			slog.Int64("allotted_ms", rec.Timeout.Milliseconds()),
+			slog.Int64("remaining_ms", rec.Remaining.Milliseconds()),
+		)
+	}
+	return rec
+}
+
+// close closes the recorder and returns the records added so far
+func (r *recorder) close() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return append([]Record(nil), r.records...)
+}
+
+// record stores rec in the run bound to ctx, if any, and prints it as a report row
+// with the formatter of the run in a single write.
+// The record is considered started at startedAt and finished now, and ran against the
+// deadline of ctx unless the process set its own.
+func record(ctx context.Context, w io.Writer, startedAt time.Time, rec Record) {
+	rec.startedAt = startedAt
+	rec.FinishedAt = now(ctx)
+	rec.Elapsed = rec.FinishedAt.Sub(startedAt)
+	rec.Depth = depth(ctx)
+	rec.Path = positionOf(ctx).path
+	if rec.Wait == 0 {
+		rec.Wait = takeWait(ctx)
+	}
+	if rec.deadline.IsZero() {
+		rec.deadline, _ = ctx.Deadline()
+	}
+	var formatter RowFormatter = tableFormatter{}
+	if r, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+		rec = r.add(rec)
+		formatter = r.formatter
+	}
+	var row bytes.Buffer
+	formatter.Row(&row, rec)
+	w.Write(row.Bytes())
+}
+
+// heldRecords denotes the records and the output of processes run under a context
+// returned by hold, they are discarded unless released
+type heldRecords struct {
+	parent *recorder
+	rec    *recorder
+	out    bytes.Buffer
+	depth  int
+}
+
+// hold returns a child of ctx whose records are held back until released, along with
+// the writer the processes run under it should print their rows to
+func hold(ctx context.Context) (context.Context, *heldRecords) {
+	h := &heldRecords{depth: depth(ctx)}
+	start, formatter := now(ctx), RowFormatter(tableFormatter{})
+	if r, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+		h.parent = r
+		start, formatter = r.start, r.formatter
+	}
+	h.rec = newRecorder(start, formatter, nil)
+	h.rec.parent = h.parent
+	return context.WithValue(ctx, recorderKey{}, h.rec), h
+}
+
+// writer returns the writer the held rows are printed to
+func (h *heldRecords) writer() io.Writer {
+	return &h.out
+}
+
+// release adds the held records to the run they were held from and prints their rows to w
+func (h *heldRecords) release(w io.Writer) {
+	records := h.rec.close()
+	if h.parent != nil {
+		for _, rec := range records {
+			h.parent.add(rec)
+		}
+	}
+	w.Write(h.out.Bytes())
+}
@@ -0,0 +1,53 @@
+package t0simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestUnexecutedRecordsKeepDeclaredParameters(t *testing.T) {
+	s := NewSimulator("declared", 50, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewFunction("b").WithTimeout(200),
+		NewFunction("c").WithTimeout(200),
+		NewFunction("d").WithDynamicContextPriority(0.5, PriorityCritical),
+	)
+	res, _ := s.Run()
+	for _, want := range []Record{
+		{Name: "b", Timeout: 200 * time.Millisecond, Available: 20 * time.Millisecond, InProgress: true},
+		{Name: "c", Timeout: 200 * time.Millisecond},
+		{Name: "d", Weight: 0.5, Priority: PriorityCritical},
+	} {
+		recs := res.ByName(want.Name)
+		if len(recs) != 1 {
+			t.Fatalf("%s: %d records", want.Name, len(recs))
+		}
+		rec := recs[0]
+		if rec.Timeout != want.Timeout || rec.Weight != want.Weight || rec.Priority != want.Priority || rec.Available != want.Available || rec.InProgress != want.InProgress {
+			t.Errorf("%s: timeout %v, weight %v, priority %v, available %v, in progress %v", rec.Name, rec.Timeout, rec.Weight, rec.Priority, rec.Available, rec.InProgress)
+		}
+	}
+	var out bytes.Buffer
+	if err := res.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var report Report
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, p := range report.Processes {
+		if p.Name == "c" {
+			found = true
+			if p.Timeout != 200 {
+				t.Errorf("c: timeout_ms %d, want 200", p.Timeout)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("c missing from the JSON report\n%s", out.String())
+	}
+}
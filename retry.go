@@ -0,0 +1,121 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the delay before a retry, attempt is 1 for the first retry
+type Backoff func(attempt int) time.Duration
+
+// FixedBackoff returns a Backoff waiting ms before every retry
+func FixedBackoff(ms int) Backoff {
+	return func(int) time.Duration {
+		return time.Duration(ms) * time.Millisecond
+	}
+}
+
+// ExponentialBackoff returns a Backoff waiting base ms before the first retry and
+// doubling the delay on every following retry
+func ExponentialBackoff(base int) Backoff {
+	return func(attempt int) time.Duration {
+		return time.Duration(base) * time.Millisecond << (attempt - 1)
+	}
+}
+
+// FunctionWithRetry denotes a function simulation retrying failed attempts against the remaining budget
+type FunctionWithRetry struct {
+	Function
+	latency     int
+	failureRate float64
+	maxAttempts int
+	backoff     Backoff
+	random      random
+}
+
+// WithRetry returns a simulated function making up to maxAttempts attempts of latency ms,
+// each failing with probability failureRate and followed by the backoff delay before the
+// next one. It panics when failureRate is not in [0,1] or maxAttempts is lower than 1.
+func (f Function) WithRetry(latency int, failureRate float64, maxAttempts int, backoff Backoff) *FunctionWithRetry {
+	if failureRate < 0 || failureRate > 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: failure rate %v out of range [0,1]", f.name, failureRate))
+	}
+	if maxAttempts < 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: max attempts %d lower than 1", f.name, maxAttempts))
+	}
+	if backoff == nil {
+		backoff = FixedBackoff(0)
+	}
+	return &FunctionWithRetry{
+		Function:    f,
+		latency:     latency,
+		failureRate: failureRate,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// WithSource sets the source failures are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithRetry) WithSource(src rand.Source) *FunctionWithRetry {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithRetry) WithSeed(seed int64) *FunctionWithRetry {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// Run runs the attempts, it stops early when the remaining budget of ctx cannot
+// cover the backoff and the latency of another attempt. Nothing is recorded when
+// ctx expires during an attempt.
+func (f *FunctionWithRetry) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	done := f.track(ctx, sp, 0)
+	latency := time.Duration(f.latency) * time.Millisecond
+	var slept time.Duration
+	attempts, succeeded := 0, false
+	for attempts < f.maxAttempts {
+		var delay time.Duration
+		if attempts > 0 {
+			delay = f.backoff(attempts)
+			if getRemaining(ctx) < delay+latency {
+				break
+			}
+		}
+		if !pause(ctx, delay+latency) {
+			return
+		}
+		slept += delay + latency
+		attempts++
+		if f.random.float64() >= f.failureRate {
+			succeeded = true
+			break
+		}
+	}
+
+	done()
+	f.finish(ctx, w, sp, Record{
+		Timeout:  slept,
+		Attempts: attempts,
+		Failed:   !succeeded,
+		Note:     fmt.Sprintf("attempts %d/%d", attempts, f.maxAttempts),
+	})
+}
+
+// IsExecuted returns true if the attempts loop has finished
+func (f *FunctionWithRetry) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithRetry) String() string {
+	return f.name
+}
+
+// Describe returns the attempts configuration of the function
+func (f *FunctionWithRetry) Describe() string {
+	return fmt.Sprintf("%d attempts of %dms, failure rate %v", f.maxAttempts, f.latency, f.failureRate)
+}
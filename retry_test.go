@@ -0,0 +1,38 @@
+package t0simulator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	for name, tt := range map[string]struct {
+		failureRate float64
+		maxAttempts int
+		attempts    int
+		failed      bool
+		consumed    time.Duration
+		note        string
+	}{
+		"first attempt succeeds": {0, 3, 1, false, 20 * time.Millisecond, "attempts 1/3"},
+		"all attempts fail":      {1, 3, 3, true, 90 * time.Millisecond, "attempts 3/3"},
+		"budget too short":       {1, 5, 3, true, 90 * time.Millisecond, "attempts 3/5"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := NewSimulator("retry", 100, WithVirtualClock(), WithVerbosity(Quiet))
+			// attempts of 20ms after backoffs of 10ms, then 20ms, then 40ms
+			s.RegisterFunctions(NewFunction("a").WithRetry(20, tt.failureRate, tt.maxAttempts, ExponentialBackoff(10)))
+			res, err := s.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := res.Records[0]
+			if !rec.Executed || rec.Attempts != tt.attempts || rec.Failed != tt.failed {
+				t.Errorf("executed %t after %d attempts, failed %t, want %d attempts, failed %t", rec.Executed, rec.Attempts, rec.Failed, tt.attempts, tt.failed)
+			}
+			if rec.Consumed != tt.consumed || rec.Note != tt.note {
+				t.Errorf("consumed %v noted %q, want %v noted %q", rec.Consumed, rec.Note, tt.consumed, tt.note)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package t0simulator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+// tabConfig denotes the configuration of the tabwriter of the table report
+type tabConfig struct {
+	minwidth int
+	padding  int
+	padchar  byte
+	debug    bool
+}
+
+var defaultTabConfig = tabConfig{minwidth: 0, padding: 1, padchar: ' ', debug: true}
+
+func newTabWriter(w io.Writer, cfg tabConfig) *tabwriter.Writer {
+	var flags uint
+	if cfg.debug {
+		flags = tabwriter.Debug
+	}
+	return tabwriter.NewWriter(w, cfg.minwidth, 0, cfg.padding, cfg.padchar, flags)
+}
+
+// WriteTable writes the result as the table printed by Run to w
+func (r *Result) WriteTable(w io.Writer) error {
+	return writeTable(w, r, tableFormatter{layout{budget: r.Budget, start: r.StartedAt}}, defaultTabConfig)
+}
+
+// Save writes the result as the table printed by Run to the file at path
+func (r *Result) Save(path string) error {
+	return r.save(path, tableFormatter{layout{budget: r.Budget, start: r.StartedAt}}, defaultTabConfig)
+}
+
+// SaveReport writes the table of the last run to the file at path, using the same
+// layout as the one printed by Run without colors
+func (s *Simulator) SaveReport(path string) error {
+	s.mu.Lock()
+	res := s.last
+	s.mu.Unlock()
+	if res == nil {
+		return ErrNoResult
+	}
+	return res.save(path, s.rowFormatter(s.layout(res.StartedAt, res.Budget, false)), s.tab)
+}
+
+func writeTable(w io.Writer, r *Result, formatter RowFormatter, tab tabConfig) error {
+	var tw *tabwriter.Writer
+	if _, ok := formatter.(tableFormatter); ok {
+		tw = newTabWriter(w, tab)
+		w = tw
+	}
+	formatter.Header(w, r.Name, r.Budget)
+	for _, rec := range r.Records {
+		if rec.Executed {
+			formatter.Row(w, rec)
+		}
+	}
+	formatter.Footer(w, r)
+	if tw != nil {
+		return tw.Flush()
+	}
+	return nil
+}
+
+// save renders the report and atomically replaces the file at path with it,
+// creating the parent directories as needed
+func (r *Result) save(path string, formatter RowFormatter, tab tabConfig) error {
+	var buf bytes.Buffer
+	if err := writeTable(&buf, r, formatter, tab); err != nil {
+		return fmt.Errorf("t0simulator: render report: %w", err)
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("t0simulator: create report directory: %w", err)
+	}
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("t0simulator: create report file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return fmt.Errorf("t0simulator: create report file: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("t0simulator: write report file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("t0simulator: write report file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("t0simulator: write report file: %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("t0simulator: save report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package t0simulator
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveReport(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("save", 100, WithVirtualClock(), WithOutput(&out))
+	s.RegisterFunctions(NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(20))
+	if err := s.SaveReport(filepath.Join(t.TempDir(), "report.txt")); !errors.Is(err, ErrNoResult) {
+		t.Fatalf("saving before a run: error %v, want ErrNoResult", err)
+	}
+	if _, err := s.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"existing directory": "report.txt",
+		"missing parents":    filepath.Join("a", "b", "report.txt"),
+	}
+	for name, rel := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, rel)
+			if err := s.SaveReport(path); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != out.String() {
+				t.Errorf("saved\n%s\nwant the report printed by Run\n%s", got, out.String())
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+				t.Errorf("%d files left in the directory, want the report only", len(entries))
+			}
+		})
+	}
+
+	t.Run("unwritable target", func(t *testing.T) {
+		dir := t.TempDir()
+		// the target is a directory holding a file, it cannot be replaced by the report
+		path := filepath.Join(dir, "report.txt")
+		if err := os.MkdirAll(filepath.Join(path, "keep"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		err := s.SaveReport(path)
+		var linkErr *os.LinkError
+		if !errors.As(err, &linkErr) || !strings.HasPrefix(err.Error(), "t0simulator: save report: ") {
+			t.Fatalf("error %v, want the failed rename", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("%d files left in the directory, want the temporary file removed", len(entries))
+		}
+	})
+
+	t.Run("parent is a file", func(t *testing.T) {
+		dir := t.TempDir()
+		parent := filepath.Join(dir, "file")
+		if err := os.WriteFile(parent, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err := s.SaveReport(filepath.Join(parent, "report.txt"))
+		var pathErr *os.PathError
+		if !errors.As(err, &pathErr) || !strings.HasPrefix(err.Error(), "t0simulator: create report directory: ") {
+			t.Fatalf("error %v, want the failed directory creation", err)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package t0simulator
+
+import "math/rand"
+
+// seeding denotes the random source a simulator seeds its processes from
+type seeding struct {
+	seed int64
+	// r is nil until the processes are seeded, at the next run
+	r *rand.Rand
+	// runs is the number of runs since the processes were seeded
+	runs int
+}
+
+// next seeds the processes ps when they are not yet, and returns the position of the
+// run about to start in the series drawn from the seed
+func (s *seeding) next(ps []Proccess) int {
+	if s.r == nil {
+		s.r = rand.New(rand.NewSource(s.seed))
+		s.runs = 0
+		for _, p := range ps {
+			seedAll(p, s.r)
+		}
+	}
+	s.runs++
+	return s.runs
+}
+
+// restart makes the processes seeded again at the next run
+func (s *seeding) restart() {
+	if s != nil {
+		s.r = nil
+	}
+}
+
+// seeder is implemented by processes and samplers consuming randomness, seed replaces
+// their source with one drawn from r
+type seeder interface {
+	seed(r *rand.Rand)
+}
+
+// seedAll seeds v with a source drawn from r when it consumes randomness, along with
+// the children of composite processes. The sources are drawn in a deterministic order,
+// so a seed of r reproduces every draw of the processes.
+func seedAll(v any, r *rand.Rand) {
+	if s, ok := v.(seeder); ok {
+		s.seed(r)
+	}
+	if c, ok := v.(Composite); ok {
+		for _, child := range c.Children() {
+			seedAll(child, r)
+		}
+	}
+}
+
+// next returns a new source drawn from r
+func next(r *rand.Rand) rand.Source {
+	return rand.NewSource(r.Int63())
+}
+
+func (f *FunctionWithTimeout) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithDynamiContext) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithRandomLatency) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithNormalLatency) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithLatencyProfile) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithRetry) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithCircuitBreaker) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (f *FunctionWithCache) seed(r *rand.Rand) {
+	f.random.setSource(next(r))
+}
+
+func (h *HistogramSampler) seed(r *rand.Rand) {
+	h.random.setSource(next(r))
+}
+
+func (b *Branching) seed(r *rand.Rand) {
+	b.random.setSource(next(r))
+}
+
+func (f *FunctionWithSampledLatency) seed(r *rand.Rand) {
+	seedAll(f.sampler, r)
+}
+
+func (f *Hedged) seed(r *rand.Rand) {
+	seedAll(f.latency, r)
+}
+
+func (f *FunctionWithQueue) seed(r *rand.Rand) {
+	seedAll(f.wait, r)
+}
+
+func (b *BackgroundProcess) seed(r *rand.Rand) {
+	seedAll(b.p, r)
+}
+
+func (c *Conditional) seed(r *rand.Rand) {
+	seedAll(c.p, r)
+}
@@ -2,16 +2,23 @@
 package t0simulator
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/rand"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"text/tabwriter"
+	"text/template"
 	"time"
 )
 
-const rowFormat = "%s\t%v\t%v\t\n"
-
 // Proccess denotes an interface of simulated process
 type Proccess interface {
 	Run(ctx context.Context, w io.Writer)
@@ -22,7 +29,29 @@ type Proccess interface {
 // Function denotes a function that will be run in simulator
 type Function struct {
 	name       string
-	isExecuted bool
+	isExecuted flag
+	succeeded  flag
+	deps       []Proccess
+	// cancellationCost is consumed when the deadline interrupts the function
+	cancellationCost time.Duration
+	// onTimeout is called when the run ends before the function finished
+	onTimeout func(remaining int64, w io.Writer)
+}
+
+// flag denotes a boolean state of a process, safe to read while the process runs in
+// another goroutine. Unlike atomic.Bool it can be copied, as functions are by their builders.
+type flag uint32
+
+func (f *flag) set(v bool) {
+	var u uint32
+	if v {
+		u = 1
+	}
+	atomic.StoreUint32((*uint32)(f), u)
+}
+
+func (f *flag) get() bool {
+	return atomic.LoadUint32((*uint32)(f)) == 1
 }
 
 // NewFunction return a new Function
@@ -32,146 +61,1056 @@ func NewFunction(name string) Function {
 	}
 }
 
-// WithTimeout returns a simulated function that will be run with context timeout
+// WithTimeout returns a simulated function that will be run with context timeout,
+// timeout is in ms, see WithTimeoutD
 func (f Function) WithTimeout(timeout int) *FunctionWithTimeout {
+	return f.WithTimeoutD(time.Duration(timeout) * time.Millisecond)
+}
+
+// WithTimeoutD returns a simulated function that will be run with context timeout
+func (f Function) WithTimeoutD(timeout time.Duration) *FunctionWithTimeout {
 	return &FunctionWithTimeout{
-		f,
-		timeout,
+		Function: f,
+		timeout:  timeout,
 	}
 }
 
-// WithDynamicContext returns a simulated function that will be run with dynamic context timeout
+// WithDynamicContext returns a simulated function that will be run with dynamic context
+// timeout, allotted weight of the remaining budget. A priority function has the
+// PriorityHigh level, PriorityNormal otherwise. It panics when weight is not in (0,1].
 func (f Function) WithDynamicContext(weight float64, isPriority bool) *FunctionWithDynamiContext {
-	return &FunctionWithDynamiContext{
-		f,
-		weight,
-		isPriority,
+	return f.WithDynamicContextPriority(weight, priorityOf(isPriority))
+}
+
+// WithDynamicContextE is like WithDynamicContext, it returns an error when weight is
+// not in (0,1]. A weight of 1 allots the whole remaining budget.
+func (f Function) WithDynamicContextE(weight float64, isPriority bool) (*FunctionWithDynamiContext, error) {
+	return f.WithDynamicContextPriorityE(weight, priorityOf(isPriority))
+}
+
+// span denotes the measure of a function run
+type span struct {
+	startedAt time.Time
+	before    time.Duration
+}
+
+// begin starts measuring a function run against ctx
+func begin(ctx context.Context) span {
+	return span{startedAt: now(ctx), before: getRemaining(ctx)}
+}
+
+// end records the row of the run measured by sp under name,
+// rec holds the fields of the row specific to the kind of process
+func (sp span) end(ctx context.Context, w io.Writer, name string, rec Record) {
+	remaining := getRemaining(ctx)
+	rec.Name = name
+	rec.Available = sp.before
+	rec.Consumed = sp.before - remaining
+	rec.Remaining = remaining
+	rec.Executed = true
+	record(ctx, w, sp.startedAt, rec)
+}
+
+// finish marks the function as executed and records the row of the run measured by sp
+func (f *Function) finish(ctx context.Context, w io.Writer, sp span, rec Record) {
+	f.isExecuted.set(true)
+	f.succeeded.set(!rec.Failed)
+	sp.end(ctx, w, f.name, rec)
+}
+
+// skip records the row of the process named name skipped against ctx
+func skip(ctx context.Context, w io.Writer, name string, rec Record) {
+	remaining := getRemaining(ctx)
+	rec.Name = name
+	rec.Available = remaining
+	rec.Remaining = remaining
+	rec.Executed = true
+	rec.Skipped = true
+	record(ctx, w, now(ctx), rec)
+}
+
+// sleep simulates a call of the function lasting d against ctx and records its row.
+// When ctx expires first, nothing is recorded and the function is reported in progress,
+// unless it has a cancellation cost to consume.
+func (f *Function) sleep(ctx context.Context, w io.Writer, d time.Duration, rec Record) {
+	sp := begin(ctx)
+	done := f.track(ctx, sp, d)
+	defer f.guardCleanup(ctx)()
+	if !pause(ctx, d) {
+		f.cleanup(ctx, w, sp, done, rec)
+		return
+	}
+	done()
+	f.finish(ctx, w, sp, rec)
+}
+
+// track marks the function as running in the run bound to ctx for the planned duration d,
+// the returned function marks it as no longer running. A function started after the
+// deadline is not tracked, it is reported unexecuted.
+func (f *Function) track(ctx context.Context, sp span, d time.Duration) func() {
+	r, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok || ctx.Err() != nil {
+		return func() {}
 	}
+	r.begin(f, sp, d)
+	return func() { r.end(f) }
+}
+
+// function returns the simulated function, it is promoted to every kind of function
+func (f *Function) function() *Function {
+	return f
+}
+
+// pause sleeps for d, it returns false when ctx expires first. A sleep ending on the
+// deadline of ctx finishes in time.
+func pause(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := clockOf(ctx).NewTimer(d)
+	defer t.Stop()
+	var ok bool
+	await(ctx, func() bool { return fired(t) || ctx.Err() != nil }, func() {
+		select {
+		case <-t.C():
+			ok = true
+		case <-ctx.Done():
+			select {
+			case <-t.C():
+				ok = true
+			default:
+			}
+		}
+	})
+	return ok
 }
 
 // FunctionWithTimeout denotes a function simulation with context timeout
 type FunctionWithTimeout struct {
 	Function
-	timeout int
+	timeout time.Duration
+	jitter  int
+	failure failure
+	random  random
+
+	skipIfOverBudget bool
+	skipped          flag
+
+	// coldStart is the extra latency in ms of the first run, warm is true once it ran
+	coldStart int
+	warm      flag
 }
 
-// Run runs the function
+// WithSkipIfOverBudget skips the function, without sleeping, when the remaining
+// budget is smaller than its timeout
+func (f *FunctionWithTimeout) WithSkipIfOverBudget() *FunctionWithTimeout {
+	f.skipIfOverBudget = true
+	return f
+}
+
+// WithJitter adds a random jitter in [-maxJitter,maxJitter] ms to the timeout on every
+// run, the slept duration is clamped at zero. It panics when maxJitter is negative.
+func (f *FunctionWithTimeout) WithJitter(maxJitter int) *FunctionWithTimeout {
+	if maxJitter < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative jitter %d", f.name, maxJitter))
+	}
+	f.jitter = maxJitter
+	return f
+}
+
+// WithFailureRate makes the function fail with probability p after consuming its time,
+// it panics when p is not in [0,1]
+func (f *FunctionWithTimeout) WithFailureRate(p float64) *FunctionWithTimeout {
+	f.failure.setRate(f.name, p)
+	return f
+}
+
+// WithSource sets the source jitter and failures are drawn from, the default source of math/rand is used otherwise
+func (f *FunctionWithTimeout) WithSource(src rand.Source) *FunctionWithTimeout {
+	f.random.setSource(src)
+	return f
+}
+
+// WithSeed sets a source seeded with seed, so runs are reproducible
+func (f *FunctionWithTimeout) WithSeed(seed int64) *FunctionWithTimeout {
+	return f.WithSource(rand.NewSource(seed))
+}
+
+// Run runs the function, the row holds the slept duration including the jitter
 func (f *FunctionWithTimeout) Run(ctx context.Context, w io.Writer) {
-	time.Sleep(time.Duration(f.timeout) * time.Millisecond)
-	f.isExecuted = true
-	fmt.Fprintf(w, rowFormat, f.name, f.timeout, getDeadline(ctx))
+	f.skipped.set(false)
+	if f.skipIfOverBudget && getRemaining(ctx) < f.timeout {
+		f.skipped.set(true)
+		f.isExecuted.set(true)
+		f.succeeded.set(false)
+		skip(ctx, w, f.name, Record{Timeout: f.timeout, Note: "insufficient budget"})
+		return
+	}
+	timeout := f.timeout
+	if f.jitter > 0 {
+		timeout += time.Duration(f.random.int63n(int64(2*f.jitter+1))-int64(f.jitter)) * time.Millisecond
+		if timeout < 0 {
+			timeout = 0
+		}
+	}
+	rec := Record{Failed: f.failure.fail(&f.random)}
+	if f.coldStart > 0 {
+		rec.Outcome = StartWarm
+		if !f.warm.get() {
+			timeout += time.Duration(f.coldStart) * time.Millisecond
+			rec.Outcome = StartCold
+			f.warm.set(true)
+		}
+		rec.Note = rec.Outcome
+	}
+	rec.Timeout = timeout
+	f.sleep(ctx, w, rec.Timeout, rec)
 }
 
-// IsExecuted returns true if function has been executed
+// IsExecuted returns true if function has been executed or skipped
 func (f *FunctionWithTimeout) IsExecuted() bool {
-	return f.isExecuted
+	return f.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run, the function stays warm, see WithColdStart
+func (f *FunctionWithTimeout) ResetRun() {
+	f.Function.ResetRun()
+	f.skipped.set(false)
+}
+
+// IsSkipped returns true if the last run skipped the function for lack of budget
+func (f *FunctionWithTimeout) IsSkipped() bool {
+	return f.skipped.get()
 }
 
 func (f *FunctionWithTimeout) String() string {
 	return f.name
 }
 
+// Describe returns the declared timeout of the function
+func (f *FunctionWithTimeout) Describe() string {
+	return fmt.Sprintf("timeout %s", f.timeout)
+}
+
+// FunctionWithBudgetShare denotes a function simulation allotted a share of the original budget
+type FunctionWithBudgetShare struct {
+	Function
+	share float64
+}
+
+// WithBudgetShare returns a simulated function allotted share of the original budget of
+// the simulator, regardless of what ran before it. It panics when share is not in [0,1].
+func (f Function) WithBudgetShare(share float64) *FunctionWithBudgetShare {
+	if share < 0 || share > 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: budget share %v out of range [0,1]", f.name, share))
+	}
+	return &FunctionWithBudgetShare{
+		Function: f,
+		share:    share,
+	}
+}
+
+// Run runs the function, the share is clamped to the remaining budget
+func (f *FunctionWithBudgetShare) Run(ctx context.Context, w io.Writer) {
+	timeout := time.Duration(float64(getBudget(ctx)) * f.share)
+	rec := Record{Weight: f.share}
+	if remaining := getRemaining(ctx); timeout > remaining {
+		timeout = remaining
+		rec.Clamped = true
+		rec.Note = "clamped"
+	}
+	rec.Timeout = timeout
+	f.sleep(ctx, w, timeout, rec)
+}
+
+// IsExecuted returns true if function has been executed
+func (f *FunctionWithBudgetShare) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+func (f *FunctionWithBudgetShare) String() string {
+	return f.name
+}
+
+// Describe returns the share of the budget of the function
+func (f *FunctionWithBudgetShare) Describe() string {
+	return fmt.Sprintf("share %v of the budget", f.share)
+}
+
 // FunctionWithDynamiContext denotes a function simulation with dynamic context timeout
 type FunctionWithDynamiContext struct {
 	Function
-	weight     float64
-	isPriority bool
+	weight   float64
+	priority Priority
+	failure  failure
+	random   random
+
+	// minTimeout and maxTimeout bound the allotted timeout in ms, zero means unbounded
+	minTimeout, maxTimeout int
+	// floor is the timeout in ms under which the function is pointless, zero for none
+	floor int
+}
+
+// WithMinTimeout sets the timeout in ms the function needs at least: a share under it is
+// raised to ms when that much is left, and the function is skipped for insufficient
+// budget otherwise. The floor takes precedence over the priority threshold, a share
+// under both is not escalated. It panics when ms is negative.
+func (f *FunctionWithDynamiContext) WithMinTimeout(ms int) *FunctionWithDynamiContext {
+	if ms < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative min timeout %d", f.name, ms))
+	}
+	f.floor = ms
+	return f
+}
+
+// WithTimeoutBounds clamps the allotted timeout to [min,max] ms, zero disables a bound.
+// It panics when the bounds are invalid.
+func (f *FunctionWithDynamiContext) WithTimeoutBounds(min, max int) *FunctionWithDynamiContext {
+	if _, err := f.WithTimeoutBoundsE(min, max); err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// WithTimeoutBoundsE clamps the allotted timeout to [min,max] ms, zero disables a bound.
+// It returns an error when a bound is negative or min is greater than max.
+func (f *FunctionWithDynamiContext) WithTimeoutBoundsE(min, max int) (*FunctionWithDynamiContext, error) {
+	if min < 0 || max < 0 {
+		return nil, fmt.Errorf("t0simulator: function %q: negative timeout bounds [%d,%d]", f.name, min, max)
+	}
+	if max > 0 && min > max {
+		return nil, fmt.Errorf("t0simulator: function %q: min timeout %d greater than max timeout %d", f.name, min, max)
+	}
+	f.minTimeout, f.maxTimeout = min, max
+	return f, nil
 }
 
 // Run runs the function
 func (f *FunctionWithDynamiContext) Run(ctx context.Context, w io.Writer) {
-	dynamicContext, esCancel := getNewContext(ctx, f.weight, f.isPriority)
+	min := time.Duration(f.minTimeout) * time.Millisecond
+	max := time.Duration(f.maxTimeout) * time.Millisecond
+	req := AllocationRequest{Name: f.name, Weight: f.weight, Priority: f.priority, MinTimeout: time.Duration(f.floor) * time.Millisecond}
+	dynamicContext, esCancel, a := getNewContext(ctx, req, min, max)
 	defer esCancel()
-	timeout := getDeadline(dynamicContext)
-	time.Sleep(time.Duration(timeout) * time.Millisecond)
-	f.isExecuted = true
-	fmt.Fprintf(w, rowFormat, f.name, timeout, getDeadline(ctx))
+	notes := priorityNote(f.priority, a.escalated, a.demoted)
+	switch {
+	case a.insufficient:
+		f.skipDependency()
+		skip(ctx, w, f.name, Record{Weight: f.weight, Priority: f.priority, Planned: a.planned, Note: strings.Join(append(notes, fmt.Sprintf("skipped, insufficient budget for %dms", f.floor)), ", ")})
+		return
+	case a.skipped:
+		f.skipDependency()
+		skip(ctx, w, f.name, Record{Weight: f.weight, Priority: f.priority, Demoted: a.demoted, Planned: a.planned, Note: strings.Join(append(notes, "skipped"), ", ")})
+		return
+	case a.floored:
+		notes = append(notes, fmt.Sprintf("raised to %dms", f.floor))
+	}
+	timeout := getRemaining(dynamicContext)
+	deadline, _ := dynamicContext.Deadline()
+	rec := Record{
+		Timeout:   timeout,
+		Weight:    f.weight,
+		Priority:  f.priority,
+		Escalated: a.escalated,
+		Demoted:   a.demoted,
+		Clamped:   a.clamped,
+		Planned:   a.planned,
+		Failed:    f.failure.fail(&f.random),
+		deadline:  deadline,
+	}
+	if a.clamped {
+		notes = append(notes, "clamped")
+	}
+	rec.Note = strings.Join(notes, ", ")
+	f.sleep(ctx, w, timeout, rec)
 }
 
 // IsExecuted returns true if function has been executed
 func (f *FunctionWithDynamiContext) IsExecuted() bool {
-	return f.isExecuted
+	return f.isExecuted.get()
 }
 
 func (f *FunctionWithDynamiContext) String() string {
 	return f.name
 }
 
+// Describe returns the weight and the priority of the function
+func (f *FunctionWithDynamiContext) Describe() string {
+	if f.priority != PriorityNormal {
+		return fmt.Sprintf("weight %v, priority %s", f.weight, f.priority)
+	}
+	return fmt.Sprintf("weight %v", f.weight)
+}
+
 // Simulator denotes a budgeting simulator
 type Simulator struct {
+	config
+
+	// running is held by the run of the registered processes, the runs started meanwhile
+	// run on copies of them
+	running sync.Mutex
+	pause   pauser
+	// writing serializes the writes of the reports of concurrent runs to the output
+	writing sync.Mutex
+
+	// mu guards the events, the last result, and the configuration against the copies
+	// of concurrent runs
+	mu     sync.Mutex
+	events chan Event
+	last   *Result
+}
+
+// config denotes the configuration of a simulator and its registered processes, it is
+// copied by Clone
+type config struct {
 	name    string
-	timeout int
-	process []Proccess
+	timeout time.Duration
+	// deadline is the absolute deadline the budget is derived from, zero for a relative budget
+	deadline time.Time
+	// reserved is the part of the budget kept back from the processes
+	reserved time.Duration
+	process  []Proccess
+	output   io.Writer
+	format   Format
+	hooks    hooks
+
+	verbosity Verbosity
+	execution Execution
+
+	color          bool
+	colorThreshold float64
+	timestamps     bool
+	drift          bool
+	formatter      RowFormatter
+	logger         *slog.Logger
+	progress       io.Writer
+	tab            tabConfig
+	template       *template.Template
+	unit           Unit
+
+	abandonBackground bool
+	abortOnPanic      bool
+	maxWallTime       time.Duration
+	virtualClock      bool
+	timeScale         float64
+	warmup            int
+	chainWeight       float64
+	middleware        []Middleware
+	deadlinePolicy    DeadlinePolicy
+	priorityThreshold time.Duration
+	allocation        AllocationStrategy
+	clock             Clock
+	seeding           *seeding
+}
+
+// Format denotes the output format of the simulator report
+type Format int
+
+const (
+	// FormatTable prints the report as a table, it is the default format
+	FormatTable Format = iota
+	// FormatJSON prints the report as a JSON document once the run is finished
+	FormatJSON
+	// FormatCSV prints the report rows as CSV once the run is finished
+	FormatCSV
+	// FormatMarkdown prints the report as a Markdown table once the run is finished
+	FormatMarkdown
+)
+
+// NewSimulator returns new simulator with a budget of timeout ms, it panics when an
+// option is invalid
+func NewSimulator(name string, timeout int, opts ...Option) *Simulator {
+	return NewSimulatorD(name, time.Duration(timeout)*time.Millisecond, opts...)
 }
 
-// NewSimulator returns new simulator
-func NewSimulator(name string, timeout int) *Simulator {
-	return &Simulator{
+// NewSimulatorE returns new simulator with a budget of timeout ms, or an error when an
+// option is invalid
+func NewSimulatorE(name string, timeout int, opts ...Option) (*Simulator, error) {
+	return NewSimulatorDE(name, time.Duration(timeout)*time.Millisecond, opts...)
+}
+
+// NewSimulatorWithDeadline returns a new simulator whose budget is the time left before
+// deadline when it runs, e.g. the deadline propagated by the request being replayed.
+// It returns an error matching ErrDeadlinePassed when deadline has passed, and an error
+// when an option is invalid.
+func NewSimulatorWithDeadline(name string, deadline time.Time, opts ...Option) (*Simulator, error) {
+	s, err := NewSimulatorDE(name, 0, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.deadline = deadline
+	if s.budget() <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDeadlinePassed, deadline.Format(time.RFC3339Nano))
+	}
+	return s, nil
+}
+
+// NewSimulatorD is like NewSimulator with a budget given as a duration, which may be
+// shorter than a millisecond
+func NewSimulatorD(name string, budget time.Duration, opts ...Option) *Simulator {
+	s, err := NewSimulatorDE(name, budget, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// NewSimulatorDE is like NewSimulatorE with a budget given as a duration
+func NewSimulatorDE(name string, budget time.Duration, opts ...Option) (*Simulator, error) {
+	s := &Simulator{config: config{
 		name:    name,
-		timeout: timeout,
+		timeout: budget,
+		output:  os.Stdout,
+		tab:     defaultTabConfig,
+
+		colorThreshold:    defaultColorThreshold,
+		priorityThreshold: defaultPriorityThreshold,
+	}}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
 	}
+	return s, nil
 }
 
-// RegisterFunctions set process need to be simulated
-func (s *Simulator) RegisterFunctions(ps ...Proccess) {
+// RegisterFunctions set process need to be simulated, replacing the ones registered
+// before. It returns an error and registers nothing when a process is nil, matching
+// ErrNilProcess, or when their dependencies form a cycle, matching ErrDependencyCycle,
+// as well as when a process nests the simulator in itself, e.g. s.AsProcess() or
+// another simulator running it.
+func (s *Simulator) RegisterFunctions(ps ...Proccess) error {
+	s.running.Lock()
+	defer s.running.Unlock()
+	if err := checkDependencies(s, ps); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.process = ps
+	s.seeding.restart()
+	return nil
 }
 
-// Run start the simulator
-func (s *Simulator) Run() {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', tabwriter.Debug)
-	fmt.Fprint(w, "=====================\n")
-	fmt.Fprintf(w, "SIMULATOR:%s\n", s.name)
-	fmt.Fprint(w, "Name\tMax Timeout(ms)\tRemaining(ms)\t\n")
-	fmt.Fprintf(w, rowFormat, "Init", s.timeout, s.timeout)
+// AddFunction appends p to the processes registered, see AddFunctions
+func (s *Simulator) AddFunction(p Proccess) error {
+	return s.AddFunctions(p)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.timeout)*time.Millisecond)
-	defer cancel()
+// AddFunctions appends ps to the processes registered, it returns an error and adds
+// nothing in the same cases as RegisterFunctions
+func (s *Simulator) AddFunctions(ps ...Proccess) error {
+	s.running.Lock()
+	defer s.running.Unlock()
+	all := append(append([]Proccess(nil), s.process...), ps...)
+	if err := checkDependencies(s, all); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.process = all
+	s.seeding.restart()
+	return nil
+}
+
+// Processes returns the processes registered
+func (s *Simulator) Processes() []Proccess {
+	s.running.Lock()
+	defer s.running.Unlock()
+	return append([]Proccess(nil), s.process...)
+}
+
+// Events returns a channel receiving the events of the next Run. Events are sent
+// without blocking on a buffered channel, which is closed when that Run returns, with
+// no event when it returned an error before starting.
+func (s *Simulator) Events() <-chan Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events == nil {
+		s.events = make(chan Event, eventBuffer)
+	}
+	return s.events
+}
 
-	done := make(chan int64, 1)
+// Run start the simulator and returns the result of the run. The error matches
+// ErrBudgetExceeded when the deadline is reached, and is also not nil when the
+// report could not be written. The state of the previous run is reset first.
+// Concurrent runs of a simulator are independent, see RunContext.
+func (s *Simulator) Run() (*Result, error) {
+	return s.RunContext(context.Background())
+}
+
+// RunContext is like Run, with a run derived from parent. The budget is the time left
+// before the deadline of parent when it is shorter than the configured one, and the
+// error matches ErrCancelled when parent is cancelled before the deadline. It returns
+// no result and an error matching ErrInvalidConfig when Validate fails, or matching
+// ErrDeadlinePassed when the absolute deadline of the simulator has passed.
+//
+// Runs of a simulator are independent: a run started while another one is in progress
+// runs on a copy of the registered processes, see Clone, which leaves them untouched.
+// The copies start from a fresh state, e.g. a closed circuit breaker or a cold function,
+// and the copies of a seeded simulator draw the series of its seed from the start.
+// Every run writes its report to the output in a single write once it is finished.
+func (s *Simulator) RunContext(parent context.Context) (*Result, error) {
+	if !s.running.TryLock() {
+		s.mu.Lock()
+		c := s.copy()
+		s.mu.Unlock()
+		return s.run(parent, &c)
+	}
+	defer s.running.Unlock()
+	return s.run(parent, &s.config)
+}
+
+// run runs the processes of c under parent, s.running must be locked when c is the
+// configuration of s. The report is written to the output of c in a single write once
+// the run is finished.
+func (s *Simulator) run(parent context.Context, c *config) (*Result, error) {
+	s.mu.Lock()
+	hr := &hookRunner{
+		name:     c.name,
+		hooks:    c.hooks,
+		events:   s.events,
+		progress: c.progress,
+		total:    len(c.process),
+	}
+	s.events = nil
+	s.mu.Unlock()
 
-	go func() {
-		for _, p := range s.process {
-			p.Run(ctx, w)
+	if err := c.validate(); err != nil {
+		hr.abort()
+		return nil, err
+	}
+	budget := c.budget()
+	if !c.deadline.IsZero() && budget <= 0 {
+		hr.abort()
+		return nil, fmt.Errorf("%w: %s", ErrDeadlinePassed, c.deadline.Format(time.RFC3339Nano))
+	}
+	if deadline, ok := parent.Deadline(); ok {
+		left := time.Until(deadline)
+		if c.clock == nil && !c.virtualClock && c.timeScale > 0 {
+			// the parent deadline is on the wall clock, the budget in the original scale
+			left = time.Duration(float64(left) * c.timeScale)
 		}
-		done <- getDeadline(ctx)
-	}()
+		budget = max(min(budget, left), 0)
+	}
+	if slice, ok := sliceOf(parent); ok {
+		budget = min(budget, slice)
+	}
+	// the processes run against the working budget, the reservation is left once they are done
+	reserved := min(c.reserved, budget)
+	budget -= reserved
+	c.reset()
+	var seedRun int
+	if c.seeding != nil {
+		seedRun = c.seeding.next(c.process)
+	}
+	switch {
+	case c.clock != nil:
+		parent = withClock(parent, c.clock)
+	case c.virtualClock:
+		vc := newVirtualClock(time.Now())
+		defer vc.close()
+		parent = withClock(parent, vc)
+	case c.timeScale > 0 && c.timeScale != 1:
+		parent = withClock(parent, scaledClock{start: time.Now(), factor: c.timeScale})
+	}
+	l := c.layout(now(parent), budget, c.color && isTerminal(c.output))
+	l.reserved = reserved
+	if hasPriority(c.process) {
+		l.priorityThreshold = c.priorityThreshold
+	}
+	pl := newPlan(c.process, budget)
+	var normalized float64
+	if _, ok := c.allocation.(planner); ok {
+		l.planned = true
+		if pl.weights > 1 {
+			normalized = pl.weights
+		}
+	}
+	l.normalized = normalized
+	formatter := c.rowFormatter(l)
+	var w io.Writer = io.Discard
+	// the report is written to a buffer of the run, so concurrent runs do not interleave
+	var report bytes.Buffer
+	var out io.Writer = &report
+	var tw *tabwriter.Writer
+	if _, ok := formatter.(tableFormatter); ok {
+		tw = newTabWriter(&report, c.tab)
+		out = tw
+	}
+	if c.format == FormatTable && c.template == nil && c.verbosity != Quiet {
+		w = out
+		formatter.Header(w, c.name, budget)
+	}
+
+	rec := newRecorder(l.start, formatter, c.logger)
+	ctx, cancel := c.deadlinePolicy.deadline(parent, budget)
+	defer cancel()
+	ctx = c.scope(context.WithValue(ctx, recorderKey{}, rec), budget, pl)
+	ctx = withChildren(ctx, c.process, c.execution == Sequential)
+	bg := &backgrounds{}
+	ctx = context.WithValue(ctx, backgroundKey{}, bg)
 
-	select {
-	case <-ctx.Done():
-		fmt.Fprint(w, "Time out reached with unexecuted function: \n")
-		for _, p := range s.process {
-			if !p.IsExecuted() {
-				fmt.Fprintf(w, "- %s\n", p.String())
+	// rows are printed through pw, closed before the footer so processes still
+	// running, e.g. the members of a parallel group, cannot print after it
+	pw := &syncWriter{w: w}
+	// done is closed once the processes are done, with the time left in timeLeft
+	done := make(chan struct{})
+	var timeLeft time.Duration
+	// progress is updated by the processes, the run reads it before they are done
+	// when the wall-time guard fires
+	var progress struct {
+		sync.Mutex
+		paused, pausedConsumed time.Duration
+		aborted                bool
+	}
+	s.pause.start()
+	defer s.pause.stop()
+
+	// step runs p, it returns false when the deadline is reached
+	current := &currentProcesses{}
+	step := func(p Proccess) bool {
+		if stopped(ctx) {
+			return false
+		}
+		before := getRemaining(ctx)
+		hr.processStart(p.String(), before)
+		current.add(p)
+		runProcess(ctx, pw, p)
+		current.remove(p)
+		if stopped(ctx) {
+			return false
+		}
+		after := getRemaining(ctx)
+		hr.processDone(p.String(), before-after, after)
+		return true
+	}
+	spawn(ctx, func() {
+		c.execute(ctx, func(p Proccess) bool {
+			if c.execution == Sequential {
+				d, consumed := s.pause.wait(ctx)
+				progress.Lock()
+				progress.paused, progress.pausedConsumed = progress.paused+d, progress.pausedConsumed+consumed
+				progress.Unlock()
+			}
+			if !step(p) {
+				return false
+			}
+			if c.execution == Sequential && c.abortOnPanic && rec.hasPanicked() {
+				progress.Lock()
+				progress.aborted = true
+				progress.Unlock()
+				return false
 			}
+			return true
+		})
+		timeLeft = getRemaining(ctx)
+		close(done)
+	})
+
+	res := &Result{
+		Name:       c.name,
+		Budget:     budget,
+		StartedAt:  l.start,
+		Execution:  c.execution,
+		Deadline:   c.deadline,
+		Reserved:   reserved,
+		Normalized: normalized,
+	}
+	if c.seeding != nil {
+		res.Seed, res.SeedRun = c.seeding.seed, seedRun
+	}
+	// the guard is on the wall clock, whatever the clock of the run
+	var guard <-chan time.Time
+	if c.maxWallTime > 0 {
+		t := time.NewTimer(c.maxWallTime)
+		defer t.Stop()
+		guard = t.C
+	}
+	var guarded, finished bool
+	await(ctx, func() bool { return closed(done) || ctx.Err() != nil }, func() {
+		select {
+		case <-guard:
+			guarded = true
+		case <-ctx.Done():
+		case <-done:
+			finished = true
+		}
+	})
+	switch {
+	case guarded:
+		// the processes in progress may never return, they are left running
+		cancel()
+		res.Abandoned, res.MaxWallTime, res.Stuck = true, c.maxWallTime, current.names()
+		res.Remaining = getRemaining(ctx)
+	case !finished:
+		await(ctx, func() bool { return closed(done) }, func() { <-done })
+		rec.waitCleanups(ctx)
+		res.TimedOut = !errors.Is(ctx.Err(), context.Canceled)
+		res.Cancelled = !res.TimedOut
+		res.Remaining = getRemaining(ctx)
+		notifyTimeout(c.process, res.Remaining.Milliseconds(), pw)
+	default:
+		res.Remaining = timeLeft
+		if c.deadlinePolicy == FinishCurrent && timeLeft <= 0 {
+			res.TimedOut = true
+			res.Overdraft = -timeLeft
+			notifyTimeout(c.process, res.Remaining.Milliseconds(), pw)
 		}
-	case timeLeft := <-done:
-		fmt.Fprintf(w, "Done with time left %v ms\n", timeLeft)
 	}
-	fmt.Fprint(w, "=====================\n")
-	w.Flush()
+	progress.Lock()
+	res.Paused, res.PausedConsumed = progress.paused, progress.pausedConsumed
+	res.Aborted = progress.aborted
+	progress.Unlock()
+
+	abandoned := bg.settle(ctx, pw, !c.abandonBackground && !res.Abandoned)
+	for _, r := range abandoned {
+		formatter.Row(pw, r)
+	}
+	pw.close()
+	res.Records = rec.close()
+	res.Records = append(res.Records, abandoned...)
+	res.Records = append(res.Records, rec.unexecuted(c.process, nil, 0, nil, now(ctx))...)
+	sortRecords(res.Records)
+	for i, r := range res.Records {
+		if r.Depth == 0 && !r.Executed && slices.Contains(res.Stuck, r.Name) {
+			res.Records[i].Started, res.Records[i].Note = true, "stuck"
+		}
+	}
+	res.summarize()
+	if c.logger != nil {
+		c.logger.Info("simulation finished",
+			slog.String("simulator", c.name),
+			slog.Bool("timed_out", res.TimedOut),
+			slog.Bool("cancelled", res.Cancelled),
+			slog.Int64("remaining_ms", res.Remaining.Milliseconds()),
+			slog.Any("unexecuted", res.Unexecuted()),
+		)
+	}
+	if res.TimedOut {
+		hr.timeout(res.Unexecuted(), res.Remaining)
+	}
+
+	var err error
+	switch {
+	case c.verbosity == Quiet:
+	case c.format == FormatJSON:
+		err = res.WriteJSON(&report)
+	case c.format == FormatCSV:
+		err = res.WriteCSV(&report)
+	case c.format == FormatMarkdown:
+		report.WriteString(res.Markdown())
+	case c.template != nil:
+		err = writeTemplate(&report, res, c.template, c.tab, l)
+	default:
+		formatter.Footer(w, res)
+		if tw != nil {
+			err = tw.Flush()
+		}
+	}
+	if report.Len() > 0 {
+		s.writing.Lock()
+		_, werr := c.output.Write(report.Bytes())
+		s.writing.Unlock()
+		err = errors.Join(err, werr)
+	}
+	hr.finish(res.Remaining)
+
+	s.mu.Lock()
+	s.last = res
+	s.mu.Unlock()
+
+	if res.TimedOut {
+		err = errors.Join(err, &BudgetExceededError{Unexecuted: res.Unexecuted()})
+	}
+	if res.Cancelled {
+		err = errors.Join(err, &CancelledError{Unexecuted: res.Unexecuted()})
+	}
+	if res.Abandoned {
+		err = errors.Join(err, &WallTimeGuardError{After: res.MaxWallTime, Stuck: res.Stuck})
+	}
+	if len(res.Summary.Violations) > 0 {
+		err = errors.Join(err, &SLOViolationError{Violations: res.Summary.Violations})
+	}
+	for _, p := range res.Panics() {
+		err = errors.Join(err, p)
+	}
+	return res, err
+}
+
+// layout returns the table layout of a run of budget started at start
+func (c *config) layout(start time.Time, budget time.Duration, color bool) layout {
+	return layout{
+		verbosity:      c.verbosity,
+		color:          color,
+		colorThreshold: c.colorThreshold,
+		timestamps:     c.timestamps,
+		drift:          c.drift,
+		unit:           c.unit.resolve(budget),
+		budget:         budget,
+		start:          start,
+		deadline:       c.deadline,
+	}
+}
+
+// budget returns the configured budget, or the time left before the absolute deadline
+func (c *config) budget() time.Duration {
+	if !c.deadline.IsZero() {
+		var now time.Time
+		if c.clock != nil {
+			now = c.clock.Now()
+		} else {
+			now = time.Now()
+		}
+		return c.deadline.Sub(now)
+	}
+	return c.timeout
+}
+
+// rowFormatter returns the configured RowFormatter, or the default table formatter with layout l
+func (c *config) rowFormatter(l layout) RowFormatter {
+	if c.formatter != nil {
+		return c.formatter
+	}
+	return tableFormatter{l}
 }
 
-func getDeadline(ctx context.Context) int64 {
+type budgetKey struct{}
+
+// scope returns a copy of ctx running the processes of c against budget: with the
+// middlewares of c after the ones of ctx, and its priority threshold, allocation
+// strategy and plan
+func (c *config) scope(ctx context.Context, budget time.Duration, pl plan) context.Context {
+	ctx = withBudget(ctx, budget)
+	ctx = withMiddleware(ctx, append(slices.Clip(middlewareOf(ctx)), c.middleware...))
+	ctx = withPriorityThreshold(ctx, c.priorityThreshold)
+	ctx = withAllocation(ctx, c.allocation)
+	return withPlan(ctx, pl)
+}
+
+// execute runs the processes of c with step under ctx, all at once with the Parallel
+// execution, or one after another until step returns false otherwise
+func (c *config) execute(ctx context.Context, step func(p Proccess) bool) {
+	if c.execution == Parallel {
+		var wg waitGroup
+		for _, p := range c.process {
+			wg.spawn(ctx, func() { step(p) })
+		}
+		wg.wait(ctx)
+		return
+	}
+	for _, p := range c.process {
+		if !step(p) {
+			return
+		}
+	}
+}
+
+// withBudget returns a child of ctx carrying the original budget of the run
+func withBudget(ctx context.Context, budget time.Duration) context.Context {
+	return context.WithValue(ctx, budgetKey{}, budget)
+}
+
+// getBudget returns the original budget of the run bound to ctx, or the time left
+// before the deadline of ctx when there is none
+func getBudget(ctx context.Context) time.Duration {
+	if budget, ok := ctx.Value(budgetKey{}).(time.Duration); ok {
+		return budget
+	}
+	return getRemaining(ctx)
+}
+
+// getRemaining returns the time left before the deadline of ctx
+func getRemaining(ctx context.Context) time.Duration {
 	deadline, _ := ctx.Deadline()
+	return deadline.Sub(now(ctx))
+}
 
-	unixTime := deadline.UnixNano()
-	diffTime := unixTime - time.Now().UnixNano()
-	diffTime = diffTime / 1e6
+// defaultPriorityThreshold is the allotment under which a priority dynamic context
+// function is allotted the whole remaining budget, see WithPriorityThreshold
+const defaultPriorityThreshold = 30 * time.Millisecond
 
-	return diffTime
+type priorityThresholdKey struct{}
+
+// withPriorityThreshold returns a copy of ctx allotting priority dynamic context functions
+// with threshold
+func withPriorityThreshold(ctx context.Context, threshold time.Duration) context.Context {
+	return context.WithValue(ctx, priorityThresholdKey{}, threshold)
 }
 
-func getNewContext(ctx context.Context, percentage float64, isPriority bool) (context.Context, context.CancelFunc) {
-	timeout := getDeadline(ctx)
-	timeoutThreshold := 30
+// priorityThresholdOf returns the priority threshold of the run bound to ctx, or the
+// default one when there is none
+func priorityThresholdOf(ctx context.Context) time.Duration {
+	if threshold, ok := ctx.Value(priorityThresholdKey{}).(time.Duration); ok {
+		return threshold
+	}
+	return defaultPriorityThreshold
+}
 
-	newTimeout := float64(timeout) * percentage
-	if newTimeout < float64(timeoutThreshold) && isPriority == true {
-		newTimeout = float64(timeout)
+// hasPriority returns true if a dynamic context function with a priority other than
+// PriorityNormal is among ps or their children
+func hasPriority(ps []Proccess) bool {
+	for _, p := range ps {
+		if f, ok := p.(*FunctionWithDynamiContext); ok && f.priority != PriorityNormal {
+			return true
+		}
+		if c, ok := p.(Composite); ok && hasPriority(c.Children()) {
+			return true
+		}
 	}
+	return false
+}
+
+// getNewContext returns a sub-context of ctx allotted to the function requesting req by
+// the allocation strategy of the run, see allocate
+func getNewContext(ctx context.Context, req AllocationRequest, min, max time.Duration) (newCtx context.Context, cancel context.CancelFunc, a allotment) {
+	req.Remaining = getRemaining(ctx)
+	req.Threshold = priorityThresholdOf(ctx)
+	req.Pending = pendingOf(ctx)
+	req.Planned = planOf(ctx).slice(req.Weight)
+	a = allocate(allocationOf(ctx), req, min, max)
+	newCtx, cancel = withTimeout(ctx, a.allotted)
+
+	return newCtx, cancel, a
+}
 
-	newCtx, cancel := context.WithTimeout(ctx, time.Duration(newTimeout)*time.Millisecond)
+// allotment denotes the timeout allotted to a dynamic context function
+type allotment struct {
+	allotted time.Duration
+	// escalated is true when an allotment under the threshold was promoted to the
+	// remaining time, demoted when it was shrunk to leave the reserved time, and skipped
+	// when what was left of it is under the threshold
+	escalated, demoted, skipped bool
+	// floored is true when the allotment was raised to the floor, insufficient when
+	// less than the floor was left
+	floored, insufficient bool
+	// clamped is true when the allotment was clamped to [min,max]
+	clamped bool
+	// planned is the slice planned upfront, see ProportionalAllocation
+	planned time.Duration
+}
 
-	return newCtx, cancel
+// allot returns the timeout allotted with percentage of the remaining time timeout, it is
+// shared by the runs and the estimates of dynamic context functions. reserved is kept
+// back for the functions of a higher priority to run: a function above PriorityNormal
+// under threshold is escalated to the time left without it, and one below has its
+// allotment shrunk to it, see Priority. A share under floor is raised to it instead,
+// whatever the priority, when timeout allows.
+func allot(timeout time.Duration, percentage float64, priority Priority, threshold, reserved, floor time.Duration) (a allotment) {
+	a.allotted = time.Duration(float64(timeout) * percentage)
+	available := timeout - reserved
+	switch {
+	case a.allotted < floor && timeout >= floor:
+		a.allotted, a.floored = floor, true
+	case a.allotted < floor:
+		a.insufficient = true
+		return a
+	case priority > PriorityNormal && a.allotted < threshold && available >= a.allotted:
+		a.allotted, a.escalated = available, true
+	case priority < PriorityNormal && a.allotted > available:
+		a.allotted, a.demoted = available, true
+		a.skipped = a.allotted < threshold
+	}
+	return a
 }
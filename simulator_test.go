@@ -0,0 +1,365 @@
+package t0simulator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWallTimeGuard(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	s := NewSimulator("guard", 10000, WithMaxWallTime(20*time.Millisecond), WithAbortOnPanic(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(1),
+		NewRealProcess("stuck", func(ctx context.Context) error {
+			<-release
+			return nil
+		}),
+	)
+	res, err := s.Run()
+	if !errors.Is(err, ErrWallTimeGuard) || errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("run returned %v, want the wall-time guard", err)
+	}
+	if !res.Abandoned || res.TimedOut || len(res.Stuck) != 1 || res.Stuck[0] != "stuck" {
+		t.Errorf("abandoned %v, timed out %v, stuck %v", res.Abandoned, res.TimedOut, res.Stuck)
+	}
+}
+
+func TestRunReportsSlowFunction(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("slow", 100, WithOutput(&out))
+	ps := []Proccess{NewFunction("a").WithTimeout(10), NewFunction("slow").WithTimeout(500), NewFunction("c").WithTimeout(5)}
+	s.RegisterFunctions(ps...)
+	// the executed state is read while the run updates it
+	stop := make(chan struct{})
+	polled := make(chan struct{})
+	go func() {
+		defer close(polled)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			for _, p := range ps {
+				p.IsExecuted()
+			}
+			runtime.Gosched()
+		}
+	}()
+	_, err := s.Run()
+	close(stop)
+	<-polled
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("run returned %v, want the budget exceeded", err)
+	}
+	for _, line := range []string{"Interrupted: slow", "Time out reached, never started: \n- c\n"} {
+		if !strings.Contains(out.String(), line) {
+			t.Errorf("report misses %q\n%s", line, out.String())
+		}
+	}
+}
+
+func TestNoRowsAfterFooter(t *testing.T) {
+	var out syncBuffer
+	s := NewSimulator("footer", 30, WithOutput(&out), WithAbandonBackground())
+	s.RegisterFunctions(
+		NewParallelGroup("g", NewFunction("a").WithTimeout(10), NewFunction("long").WithTimeout(80)),
+		Background(NewFunction("late").WithTimeout(60)),
+	)
+	s.Run()
+	// give the abandoned processes the time to finish
+	time.Sleep(80 * time.Millisecond)
+	report := out.String()
+	footer := strings.LastIndex(report, "=====================\n")
+	if footer < 0 || footer+len("=====================\n") != len(report) || strings.Count(report, "=====================\n") != 2 {
+		t.Errorf("rows written after the footer\n%s", report)
+	}
+}
+
+func TestConcurrentRuns(t *testing.T) {
+	var out syncBuffer
+	s := NewSimulator("concurrent", 100, WithVirtualClock(), WithOutput(&out))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewParallelGroup("g", NewFunction("b").WithTimeout(20), NewFunction("c").WithTimeout(40)),
+		NewFunction("d").WithDynamicContext(0.5, false),
+	)
+	const runs = 50
+	results := make([]*Result, runs)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = s.Run()
+		}()
+	}
+	wg.Wait()
+	own := make([]bytes.Buffer, runs)
+	for i, res := range results {
+		if res.TimedOut || res.Remaining != 15*time.Millisecond || len(res.Records) != 5 {
+			t.Errorf("run %d: timed out %v, remaining %v, %d records", i, res.TimedOut, res.Remaining, len(res.Records))
+		}
+		res.WriteTable(&own[i])
+		if own[i].String() != own[0].String() {
+			t.Errorf("report of run %d differs\n%s\nfrom\n%s", i, own[i].String(), own[0].String())
+		}
+	}
+	reports := strings.SplitAfter(out.String(), "Executed 3 of 3 functions\n=====================\n")
+	if len(reports) != runs+1 || reports[runs] != "" {
+		t.Fatalf("%d reports, want %d\n%s", len(reports)-1, runs, out.String())
+	}
+	for i, report := range reports[:runs] {
+		if !strings.HasPrefix(report, "=====================\nSIMULATOR:concurrent\n") || report != reports[0] {
+			t.Errorf("report %d differs\n%s\nfrom\n%s", i, report, reports[0])
+		}
+	}
+}
+
+func TestConcurrentRunsIndependent(t *testing.T) {
+	const runs = 10
+	// every run waits for the others to reach its process, serialized runs would time out
+	var arrived sync.WaitGroup
+	arrived.Add(runs)
+	all := make(chan struct{})
+	go func() {
+		arrived.Wait()
+		close(all)
+	}()
+	s := NewSimulator("independent", 5000, WithVerbosity(Quiet))
+	s.RegisterFunctions(NewRealProcess("wait", func(ctx context.Context) error {
+		arrived.Done()
+		select {
+		case <-all:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}))
+	results := make([]*Result, runs)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = s.Run()
+		}()
+	}
+	wg.Wait()
+	for i, res := range results {
+		if res.TimedOut || len(res.Records) != 1 || !res.Records[0].Executed || res.Records[0].Failed {
+			t.Errorf("run %d: timed out %v, records %+v", i, res.TimedOut, res.Records)
+		}
+	}
+}
+
+func TestTimedOutRunsLeaveNoGoroutine(t *testing.T) {
+	run := func() {
+		s := NewSimulator("leak", 5, WithVerbosity(Quiet))
+		s.RegisterFunctions(
+			NewFunction("a").WithTimeout(2),
+			NewParallelGroup("g", NewFunction("b").WithTimeout(20), NewFunction("c").WithTimeout(30)),
+			NewFunction("d").WithTimeout(10),
+		)
+		if res, _ := s.Run(); !res.TimedOut {
+			t.Fatal("run did not time out")
+		}
+	}
+	run()
+	before := runtime.NumGoroutine()
+	for range 100 {
+		run()
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines after 100 timed-out runs, %d before", after, before)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestRegisterNilProcess(t *testing.T) {
+	var f *FunctionWithTimeout
+	for name, ps := range map[string][]Proccess{
+		"nil":         {nil},
+		"typed nil":   {f},
+		"nested":      {NewSequentialGroup("g", NewFunction("a").WithTimeout(1), f)},
+		"after valid": {NewFunction("a").WithTimeout(1), f},
+	} {
+		s := NewSimulator(name, 100, WithVerbosity(Quiet))
+		if err := s.RegisterFunctions(ps...); !errors.Is(err, ErrNilProcess) {
+			t.Errorf("%s: RegisterFunctions returned %v, want ErrNilProcess", name, err)
+		}
+		if err := s.AddFunctions(ps...); !errors.Is(err, ErrNilProcess) {
+			t.Errorf("%s: AddFunctions returned %v, want ErrNilProcess", name, err)
+		}
+		if len(s.Processes()) != 0 {
+			t.Errorf("%s: %d processes registered", name, len(s.Processes()))
+		}
+	}
+	if _, err := OverrideTimeout(f, 10); !errors.Is(err, ErrNilProcess) {
+		t.Errorf("OverrideTimeout returned %v, want ErrNilProcess", err)
+	}
+}
+
+func TestRunErrorsWithoutUnexecuted(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want string
+	}{
+		{&BudgetExceededError{}, "t0simulator: budget exceeded"},
+		{&BudgetExceededError{Unexecuted: []string{"a", "b"}}, "t0simulator: budget exceeded with unexecuted function: a, b"},
+		{&CancelledError{}, "t0simulator: cancelled by caller"},
+		{&CancelledError{Unexecuted: []string{"a"}}, "t0simulator: cancelled by caller with unexecuted function: a"},
+		{&WallTimeGuardError{After: time.Second}, "t0simulator: wall-time guard after 1s"},
+		{&WallTimeGuardError{After: time.Second, Stuck: []string{"a"}}, "t0simulator: wall-time guard after 1s, stuck in: a"},
+	} {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestEventsClosedWhenRunFailsToStart(t *testing.T) {
+	late, err := NewSimulatorWithDeadline("late", time.Now().Add(10*time.Millisecond), WithVerbosity(Quiet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	late.RegisterFunctions(NewFunction("a").WithTimeout(1))
+	time.Sleep(20 * time.Millisecond)
+	tests := map[string]struct {
+		s    *Simulator
+		want error
+	}{
+		"invalid config":  {s: NewSimulator("empty", 100, WithVerbosity(Quiet)), want: ErrInvalidConfig},
+		"deadline passed": {s: late, want: ErrDeadlinePassed},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			events := tt.s.Events()
+			if _, err := tt.s.Run(); !errors.Is(err, tt.want) {
+				t.Fatalf("error %v, want %v", err, tt.want)
+			}
+			var got []Event
+			for e := range events {
+				got = append(got, e)
+			}
+			if len(got) != 0 {
+				t.Errorf("events %+v, want none", got)
+			}
+		})
+	}
+}
+
+func TestRunContextCancelled(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewSimulator("cancelled", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewRealProcess("cancel", func(ctx context.Context) error {
+			cancel()
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+		NewFunction("a").WithTimeout(10),
+	)
+	res, err := s.RunContext(parent)
+	if !errors.Is(err, ErrCancelled) || errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("error %v, want ErrCancelled only", err)
+	}
+	if !res.Cancelled || res.TimedOut {
+		t.Errorf("cancelled %v, timed out %v", res.Cancelled, res.TimedOut)
+	}
+}
+
+func TestRunContextParentDeadline(t *testing.T) {
+	tests := map[string]struct {
+		opts []Option
+		// the budget is at most max, the time left before the deadline of the parent
+		max time.Duration
+	}{
+		"wall clock": {max: 50 * time.Millisecond},
+		// the deadline of the parent is on the wall clock, ten times shorter than the budget
+		"time scale": {opts: []Option{WithTimeScale(10)}, max: 500 * time.Millisecond},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			parent, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			s := NewSimulator("parent", 1000, append([]Option{WithVerbosity(Quiet)}, tt.opts...)...)
+			s.RegisterFunctions(NewFunction("a").WithTimeout(1))
+			res, err := s.RunContext(parent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Budget > tt.max || res.Budget < tt.max*4/5 {
+				t.Errorf("budget %v, want at most %v", res.Budget, tt.max)
+			}
+		})
+	}
+}
+
+func TestRandSeedReproducible(t *testing.T) {
+	draws := func(seed int64) []string {
+		t.Helper()
+		s := NewSimulator("seeded", 10000, WithVirtualClock(), WithVerbosity(Quiet), WithRandSeed(seed))
+		for _, name := range []string{"a", "b", "c", "d"} {
+			s.AddFunction(NewFunction(name).WithTimeout(100).WithJitter(50).WithFailureRate(0.5))
+		}
+		var got []string
+		for run := 0; run < 2; run++ {
+			res, _ := s.Run()
+			for _, rec := range res.Records {
+				got = append(got, fmt.Sprintf("%s %v %v", rec.Name, rec.Timeout, rec.Failed))
+			}
+		}
+		return got
+	}
+	first, again, other := draws(1), draws(1), draws(2)
+	if !slices.Equal(first, again) {
+		t.Errorf("seed 1 drew %v, then %v", first, again)
+	}
+	if slices.Equal(first, other) {
+		t.Errorf("seeds 1 and 2 both drew %v", first)
+	}
+}
+
+func TestLargestConsumerFooter(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("largest", 100, WithVirtualClock(), WithOutput(&out))
+	// b is skipped, its row shows its timeout of 200 ms but it consumed nothing
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(30),
+		NewFunction("b").WithTimeout(200).WithSkipIfOverBudget(),
+	)
+	if _, err := s.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Largest consumer: a (consumed 30 ms)\n") {
+		t.Errorf("report misses the time consumed by a\n%s", out.String())
+	}
+}
@@ -0,0 +1,85 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// FunctionWithStreaming denotes a function simulation streaming its response in chunks
+type FunctionWithStreaming struct {
+	Function
+	chunks       int
+	chunkLatency int
+	delivered    atomic.Int64
+}
+
+// WithStreaming returns a simulated function delivering chunks chunks, one every
+// chunkLatency ms. It panics when chunks is lower than 1 or chunkLatency is negative.
+func (f Function) WithStreaming(chunks, chunkLatency int) *FunctionWithStreaming {
+	if chunks < 1 {
+		panic(fmt.Sprintf("t0simulator: function %q: chunk count %d lower than 1", f.name, chunks))
+	}
+	if chunkLatency < 0 {
+		panic(fmt.Sprintf("t0simulator: function %q: negative chunk latency %d", f.name, chunkLatency))
+	}
+	return &FunctionWithStreaming{
+		Function:     f,
+		chunks:       chunks,
+		chunkLatency: chunkLatency,
+	}
+}
+
+// Run delivers the chunks until all of them are delivered or ctx expires. An interrupted
+// stream is reported in progress along with the chunks delivered so far.
+func (f *FunctionWithStreaming) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	latency := time.Duration(f.chunkLatency) * time.Millisecond
+	f.delivered.Store(0)
+	done := f.track(ctx, sp, time.Duration(f.chunks)*latency)
+	for i := 0; i < f.chunks; i++ {
+		if !pause(ctx, latency) {
+			return
+		}
+		f.delivered.Add(1)
+	}
+	done()
+	f.finish(ctx, w, sp, Record{
+		Timeout:   time.Duration(f.chunks) * latency,
+		Delivered: f.chunks,
+		Chunks:    f.chunks,
+		Note:      fmt.Sprintf("%d/%d chunks", f.chunks, f.chunks),
+	})
+}
+
+// Delivered returns the number of chunks delivered by the last run
+func (f *FunctionWithStreaming) Delivered() int {
+	return int(f.delivered.Load())
+}
+
+// progress returns the chunks delivered so far out of the total
+func (f *FunctionWithStreaming) progress() (delivered, total int) {
+	return f.Delivered(), f.chunks
+}
+
+// IsExecuted returns true once all the chunks have been delivered
+func (f *FunctionWithStreaming) IsExecuted() bool {
+	return f.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (f *FunctionWithStreaming) ResetRun() {
+	f.Function.ResetRun()
+	f.delivered.Store(0)
+}
+
+func (f *FunctionWithStreaming) String() string {
+	return f.name
+}
+
+// Describe returns the chunks of the function
+func (f *FunctionWithStreaming) Describe() string {
+	return fmt.Sprintf("%d chunks of %dms", f.chunks, f.chunkLatency)
+}
@@ -0,0 +1,363 @@
+package t0simulator
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	colorDefault = "\x1b[39m"
+	colorYellow  = "\x1b[33m"
+	colorRed     = "\x1b[31m"
+	colorReset   = "\x1b[0m"
+)
+
+// defaultColorThreshold is the percentage of the budget under which a row is highlighted
+const defaultColorThreshold = 20
+
+// RowFormatter denotes the formatter of the report printed while the simulator runs.
+// Header is written before the first process runs, Row every time a process is done
+// and Footer once the run is finished.
+type RowFormatter interface {
+	Header(w io.Writer, name string, budget time.Duration)
+	Row(w io.Writer, rec Record)
+	Footer(w io.Writer, res *Result)
+}
+
+// tableFormatter is the default RowFormatter, it prints the rows as tabwriter cells
+type tableFormatter struct {
+	layout
+}
+
+func (f tableFormatter) Header(w io.Writer, name string, budget time.Duration) {
+	f.budget = budget
+	writeHeader(w, name, f.layout)
+}
+
+func (f tableFormatter) Row(w io.Writer, rec Record) {
+	writeRow(w, rec, f.layout)
+}
+
+func (f tableFormatter) Footer(w io.Writer, res *Result) {
+	writeFooter(w, res, f.layout)
+}
+
+// layout denotes how the report table is rendered
+type layout struct {
+	verbosity Verbosity
+	// color enables ANSI colors, every colored line starts with a color code of
+	// the same length so the tabwriter columns stay aligned
+	color          bool
+	colorThreshold float64
+	// timestamps appends the wall-clock time at which each row finished
+	timestamps bool
+	// drift appends the measured elapsed time of each row and its drift from the timeout
+	drift  bool
+	unit   Unit
+	budget time.Duration
+	start  time.Time
+	// deadline is the absolute deadline of the run, zero for a relative budget
+	deadline time.Time
+	// reserved is the part of the budget kept back from the processes
+	reserved time.Duration
+	// priorityThreshold is the threshold of priority dynamic context functions, zero
+	// when none is registered
+	priorityThreshold time.Duration
+	// planned appends the slice planned upfront for each row and its consumption, see
+	// ProportionalAllocation, and normalized is the sum of the weights when it was over 1
+	planned    bool
+	normalized float64
+}
+
+// rowColor returns the color of a row with the given remaining budget
+func (l layout) rowColor(remaining time.Duration) string {
+	switch {
+	case remaining <= 0:
+		return colorRed
+	case float64(remaining) < float64(l.budget)*l.colorThreshold/100:
+		return colorYellow
+	}
+	return colorDefault
+}
+
+// writeRow writes a table row of tab terminated cells, colored with c when colors are enabled
+func (l layout) writeRow(w io.Writer, c string, cells ...interface{}) {
+	if l.color {
+		fmt.Fprint(w, c)
+	}
+	for _, cell := range cells {
+		fmt.Fprintf(w, "%v\t", cell)
+	}
+	if l.color {
+		fmt.Fprint(w, colorReset)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+// writeText writes a line of text, colored with c when colors are enabled
+func (l layout) writeText(w io.Writer, c, text string) {
+	if l.color {
+		text = c + text + colorReset
+	}
+	fmt.Fprintln(w, text)
+}
+
+// isTerminal returns true if w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func writeHeader(w io.Writer, name string, l layout) {
+	fmt.Fprint(w, "=====================\n")
+	fmt.Fprintf(w, "SIMULATOR:%s\n", name)
+	u, budget := l.unit, l.unit.format(l.budget)
+	header := []interface{}{"Name", "Max Timeout(" + u.String() + ")", "Remaining(" + u.String() + ")", "Status"}
+	init := []interface{}{"Init", budget, budget, ""}
+	if l.verbosity == Verbose {
+		for _, column := range []string{"Start", "End", "Deadline", "Available", "Consumed", "Wait"} {
+			header = append(header, column+"("+u.String()+")")
+		}
+		init = append(init, u.format(0), u.format(0), budget, budget, u.format(0), u.format(0))
+	}
+	if l.planned {
+		header = append(header, "Planned("+u.String()+")")
+		init = append(init, "")
+		if l.verbosity != Verbose {
+			header = append(header, "Consumed("+u.String()+")")
+			init = append(init, u.format(0))
+		}
+	}
+	if l.drift {
+		header = append(header, "Elapsed("+u.String()+")", "Drift("+u.String()+")")
+		init = append(init, "", "")
+	}
+	if l.timestamps {
+		header = append(header, "Time")
+		init = append(init, l.start.Format(time.RFC3339Nano))
+	}
+	var notes []string
+	if !l.deadline.IsZero() {
+		notes = append(notes, "deadline "+l.deadline.Format(time.RFC3339Nano))
+	}
+	if l.reserved > 0 {
+		notes = append(notes, fmt.Sprintf("%s %s reserved of %s %s", u.format(l.reserved), u, u.format(l.budget+l.reserved), u))
+	}
+	if l.priorityThreshold > 0 {
+		notes = append(notes, fmt.Sprintf("priority threshold %s %s", u.format(l.priorityThreshold), u))
+	}
+	if l.normalized > 0 {
+		notes = append(notes, normalizedNote(l.normalized))
+	}
+	if len(notes) > 0 {
+		init = append(init, strings.Join(notes, ", "))
+	}
+	l.writeRow(w, colorDefault, header...)
+	l.writeRow(w, colorDefault, init...)
+}
+
+func writeRow(w io.Writer, rec Record, l layout) {
+	u := l.unit
+	cells := []interface{}{strings.Repeat("  ", rec.Depth) + rec.Name, u.format(rec.Timeout), u.format(rec.Remaining), rec.Status()}
+	if l.verbosity == Verbose {
+		cells = append(cells, u.format(rec.Start), u.format(rec.End), u.format(rec.DeadlineAt), u.format(rec.Available), u.format(rec.Consumed), u.format(rec.Wait))
+	}
+	if l.planned {
+		planned := ""
+		if rec.Planned > 0 {
+			planned = u.format(rec.Planned)
+		}
+		cells = append(cells, planned)
+		if l.verbosity != Verbose {
+			cells = append(cells, u.format(rec.Consumed))
+		}
+	}
+	if l.drift {
+		elapsed, drift := "", ""
+		if rec.Executed || rec.InProgress {
+			elapsed, drift = u.format(rec.Elapsed), u.format(rec.Drift())
+			if rec.Drift() >= 0 {
+				drift = "+" + drift
+			}
+		}
+		cells = append(cells, elapsed, drift)
+	}
+	if l.timestamps {
+		cells = append(cells, rec.FinishedAt.Format(time.RFC3339Nano))
+	}
+	// the time waited is only a column of the verbose table, the default one notes it
+	// unless the process does, e.g. a queued function along with its service time
+	var notes []string
+	if rec.Note != "" {
+		notes = append(notes, rec.Note)
+	}
+	if rec.Wait > 0 && rec.Service == 0 && l.verbosity != Verbose {
+		notes = append(notes, fmt.Sprintf("waited %s %s", u.format(rec.Wait), u))
+	}
+	if len(notes) > 0 {
+		cells = append(cells, strings.Join(notes, ", "))
+	}
+	l.writeRow(w, l.rowColor(rec.Remaining), cells...)
+}
+
+func writeFooter(w io.Writer, res *Result, l layout) {
+	if res.TimedOut || res.Cancelled || res.Aborted || res.Abandoned {
+		for _, rec := range res.Records {
+			switch {
+			case rec.State() != StateInterrupted:
+				continue
+			case rec.Partial():
+				fmt.Fprintf(w, "partial: %s (%d/%d chunks)\n", rec.Name, rec.Delivered, rec.Chunks)
+				continue
+			}
+			switch {
+			case !rec.InProgress:
+				fmt.Fprintf(w, "Interrupted: %s (started, not finished)\n", rec.Name)
+			case rec.Timeout > 0:
+				fmt.Fprintf(w, "Interrupted: %s after %s%s of %s%s (started, not finished)\n", rec.Name, l.unit.format(rec.Consumed), l.unit, l.unit.format(rec.Timeout), l.unit)
+			default:
+				fmt.Fprintf(w, "Interrupted: %s after %s%s (started, not finished)\n", rec.Name, l.unit.format(rec.Consumed), l.unit)
+			}
+		}
+		outcome := "Time out reached"
+		switch {
+		case res.Abandoned:
+			outcome = guardNote(res)
+		case res.Aborted:
+			outcome = "Aborted after a panic"
+		case !res.TimedOut:
+			outcome = "Cancelled by caller"
+		}
+		if len(res.NeverStarted()) == 0 {
+			l.writeText(w, colorRed, outcome)
+		} else {
+			l.writeText(w, colorRed, outcome+", never started: ")
+		}
+		writeNeverStarted(w, res.Records)
+	} else {
+		fmt.Fprintf(w, "Done with time left %s %s\n", l.unit.format(res.Remaining), l.unit)
+	}
+	if res.Overdraft > 0 {
+		fmt.Fprintf(w, "%s\n", overdraftNote(res, l.unit))
+	}
+	if res.Reserved > 0 {
+		fmt.Fprintf(w, "%s\n", reservedNote(res, l.unit))
+	}
+	if res.Paused > 0 {
+		fmt.Fprintf(w, "%s\n", pausedNote(res, l.unit))
+	}
+	if len(res.Summary.Violations) > 0 {
+		l.writeText(w, colorRed, "SLO violated: ")
+		for _, v := range res.Summary.Violations {
+			fmt.Fprintf(w, "- %s\n", v)
+		}
+	}
+	writeSummary(w, res, l.unit)
+	fmt.Fprint(w, "=====================\n")
+}
+
+// guardNote returns the outcome of a run abandoned by the wall-time guard
+func guardNote(res *Result) string {
+	if len(res.Stuck) == 0 {
+		return fmt.Sprintf("Aborted: wall-time guard after %s", res.MaxWallTime)
+	}
+	return fmt.Sprintf("Aborted: wall-time guard after %s, stuck in %s", res.MaxWallTime, strings.Join(res.Stuck, ", "))
+}
+
+// reservedNote returns the line of the report telling what is left of the reservation
+func reservedNote(res *Result, u Unit) string {
+	if res.TimedOut {
+		return fmt.Sprintf("Reserved %s %s still available for the response, the processes stopped at the working deadline", u.format(res.Reserved), u)
+	}
+	return fmt.Sprintf("Reserved %s %s unused, %s %s left for the response", u.format(res.Reserved), u, u.format(res.Reserved+max(res.Remaining, 0)), u)
+}
+
+// normalizedNote returns the warning of weights normalized by ProportionalAllocation
+func normalizedNote(weights float64) string {
+	return fmt.Sprintf("warning: weights sum to %.3g, normalized", weights)
+}
+
+// pausedNote returns the line of the report telling how long the run was paused
+func pausedNote(res *Result, u Unit) string {
+	if res.PausedConsumed <= 0 {
+		return fmt.Sprintf("Paused %s %s, the budget clock was stopped", u.format(res.Paused), u)
+	}
+	return fmt.Sprintf("Paused %s %s, %s %s of them consumed from the budget", u.format(res.Paused), u, u.format(res.PausedConsumed), u)
+}
+
+// writeNeverStarted writes the list of the records never started, nested under their
+// parent when it never started either, and annotated with it otherwise
+func writeNeverStarted(w io.Writer, records []Record) {
+	// indents holds the indentation of the records at every depth of the current branch,
+	// -1 for the interrupted ones, parents the names of the records
+	var indents []int
+	var parents []string
+	for _, rec := range records {
+		if rec.Executed {
+			continue
+		}
+		for len(indents) < rec.Depth {
+			indents, parents = append(indents, -1), append(parents, "")
+		}
+		indents, parents = append(indents[:rec.Depth], -1), append(parents[:rec.Depth], rec.Name)
+		if rec.State() != StateNotStarted {
+			continue
+		}
+		indent, notes := 0, []string{}
+		if rec.Depth > 0 {
+			if parent := indents[rec.Depth-1]; parent >= 0 {
+				indent = parent + 1
+			} else if parents[rec.Depth-1] != "" {
+				notes = append(notes, "in "+parents[rec.Depth-1])
+			}
+		}
+		indents[rec.Depth] = indent
+		if rec.Note != "" {
+			notes = append(notes, rec.Note)
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(w, "%s- %s (%s)\n", strings.Repeat("  ", indent), rec.Name, strings.Join(notes, ", "))
+		} else {
+			fmt.Fprintf(w, "%s- %s\n", strings.Repeat("  ", indent), rec.Name)
+		}
+	}
+}
+
+func writeSummary(w io.Writer, res *Result, u Unit) {
+	sum := res.Summary
+	utilization := fmt.Sprintf("%.1f%%", sum.Utilization)
+	if res.TimedOut {
+		utilization = "exceeded"
+	}
+	fmt.Fprintf(w, "Consumed %s %s of %s %s (%s)\n", u.format(sum.Consumed), u, u.format(res.Budget), u, utilization)
+	if sum.LargestConsumer != "" {
+		fmt.Fprintf(w, "Largest consumer: %s (consumed %s %s)\n", sum.LargestConsumer, u.format(sum.LargestConsumed), u)
+	}
+	if res.Execution == Parallel && sum.CriticalPath != "" {
+		fmt.Fprintf(w, "Makespan %s %s, critical path: %s\n", u.format(sum.Makespan), u, sum.CriticalPath)
+	}
+	if sum.Overhead > 0 {
+		fmt.Fprintf(w, "Overhead: %s %s, dependencies: %s %s\n", u.format(sum.Overhead), u, u.format(sum.Dependencies), u)
+	}
+	fmt.Fprintf(w, "Executed %d of %d functions", sum.Executed, sum.Registered)
+	if sum.Failed > 0 {
+		fmt.Fprintf(w, ", %d failed", sum.Failed)
+	}
+	if sum.Skipped > 0 {
+		fmt.Fprintf(w, ", %d skipped", sum.Skipped)
+	}
+	if sum.Partial > 0 {
+		fmt.Fprintf(w, ", %d partial", sum.Partial)
+	}
+	fmt.Fprint(w, "\n")
+}
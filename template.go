@@ -0,0 +1,80 @@
+package t0simulator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultReportTemplate renders the same table as the default output of Run, through
+// the header, row and footer functions of TemplateFuncs. Report templates are executed
+// against the *Result of the run and their output goes through the tabwriter, so tab
+// terminated cells are aligned.
+var DefaultReportTemplate = template.Must(template.New("report").Funcs(TemplateFuncs).Parse(
+	`{{header .}}{{range .Records}}{{if .Executed}}{{row .}}{{end}}{{end}}{{footer .}}`,
+))
+
+// TemplateFuncs are the functions available to DefaultReportTemplate, "ms" returns a
+// duration as a whole number of milliseconds, "header", "row" and "footer" render the
+// parts of the table printed by Run, in the layout of the run executing the template
+var TemplateFuncs = tableFuncs(layout{})
+
+// tableFuncs returns the template functions rendering the table in the layout l
+func tableFuncs(l layout) template.FuncMap {
+	f := tableFormatter{l}
+	return template.FuncMap{
+		"ms": func(d time.Duration) int64 {
+			return d.Milliseconds()
+		},
+		"header": func(r *Result) string {
+			var b strings.Builder
+			f := f
+			if f.start.IsZero() {
+				// outside of a run the layout is taken from the result
+				f.start, f.deadline, f.reserved, f.normalized = r.StartedAt, r.Deadline, r.Reserved, r.Normalized
+			}
+			f.Header(&b, r.Name, r.Budget)
+			return b.String()
+		},
+		"row": func(rec Record) string {
+			var b strings.Builder
+			f.Row(&b, rec)
+			return b.String()
+		},
+		"footer": func(r *Result) string {
+			var b strings.Builder
+			f.Footer(&b, r)
+			return b.String()
+		},
+	}
+}
+
+// WithReportTemplate renders the report with tmpl once the run is finished, instead
+// of printing the table while the simulator runs
+func WithReportTemplate(tmpl *template.Template) Option {
+	return func(s *Simulator) error {
+		s.template = tmpl
+		return nil
+	}
+}
+
+// writeTemplate executes tmpl against r with the table functions in the layout l and
+// writes its output to w, nothing is written when the execution fails
+func writeTemplate(w io.Writer, r *Result, tmpl *template.Template, tab tabConfig, l layout) error {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return fmt.Errorf("t0simulator: execute report template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(tableFuncs(l)).Execute(&buf, r); err != nil {
+		return fmt.Errorf("t0simulator: execute report template: %w", err)
+	}
+	tw := newTabWriter(w, tab)
+	if _, err := tw.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return tw.Flush()
+}
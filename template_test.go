@@ -0,0 +1,48 @@
+package t0simulator
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDefaultReportTemplateMatchesTable(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts []Option
+		ps   []Proccess
+	}{
+		"reserved": {
+			opts: []Option{WithReservedBudget(20)},
+			ps:   []Proccess{NewFunction("a").WithTimeout(30), NewFunction("b").WithDynamicContextPriority(0.5, PriorityCritical)},
+		},
+		"overdraft": {
+			opts: []Option{WithDeadlinePolicy(FinishCurrent)},
+			ps:   []Proccess{NewFunction("a").WithTimeout(60), NewFunction("b").WithTimeout(60), NewFunction("c").WithTimeout(10)},
+		},
+		"normalized": {
+			opts: []Option{WithAllocationStrategy(ProportionalAllocation{})},
+			ps:   []Proccess{NewFunction("a").WithDynamicContext(0.6, false), NewFunction("b").WithDynamicContext(0.6, false)},
+		},
+		"timeout": {
+			ps: []Proccess{NewSequentialGroup("g", NewFunction("a").WithTimeout(60), NewFunction("b").WithTimeout(60)), NewFunction("c").WithTimeout(10)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var table, report bytes.Buffer
+			for _, out := range []struct {
+				w    *bytes.Buffer
+				opts []Option
+			}{
+				{&table, nil},
+				{&report, []Option{WithReportTemplate(DefaultReportTemplate)}},
+			} {
+				opts := append([]Option{WithVirtualClock(), WithOutput(out.w)}, tc.opts...)
+				s := NewSimulator(name, 100, append(opts, out.opts...)...)
+				s.RegisterFunctions(tc.ps...)
+				s.Run()
+			}
+			if table.String() != report.String() {
+				t.Errorf("template rendered\n%s\ntable\n%s", report.String(), table.String())
+			}
+		})
+	}
+}
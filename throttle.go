@@ -0,0 +1,150 @@
+package t0simulator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ThrottledGroup denotes a process running its children one after another, each start
+// waiting for a token of a limit per interval rate limiter
+type ThrottledGroup struct {
+	limit    int
+	per      time.Duration
+	children []Proccess
+
+	isExecuted flag
+	// waiting is the index of the child waiting for a token, -1 otherwise
+	waiting atomic.Int64
+}
+
+// Throttled returns a process running children in order, starting at most limit of them
+// per interval. It panics when limit is lower than 1 or per is not positive.
+func Throttled(limit int, per time.Duration, children ...Proccess) *ThrottledGroup {
+	if limit < 1 || per <= 0 {
+		panic(fmt.Sprintf("t0simulator: throttled group: invalid rate %d per %s", limit, per))
+	}
+	g := &ThrottledGroup{limit: limit, per: per, children: children}
+	g.waiting.Store(-1)
+	return g
+}
+
+// Run runs the children, the time waited for a token is charged against the budget
+// and reported in the Wait column of the child. It stops when ctx expires while waiting.
+func (g *ThrottledGroup) Run(ctx context.Context, w io.Writer) {
+	sp := begin(ctx)
+	g.isExecuted.set(false)
+	g.waiting.Store(-1)
+	child := nested(ctx)
+	interval := g.per / time.Duration(g.limit)
+	tokens, last := float64(g.limit), now(ctx)
+	var waited time.Duration
+	for i, c := range g.children {
+		at := now(ctx)
+		tokens += float64(at.Sub(last)) / float64(interval)
+		if tokens > float64(g.limit) {
+			tokens = float64(g.limit)
+		}
+		last = at
+
+		var wait time.Duration
+		if tokens < 1 {
+			wait = time.Duration((1 - tokens) * float64(interval))
+			g.waiting.Store(int64(i))
+			t := clockOf(ctx).NewTimer(wait)
+			expired := false
+			await(ctx, func() bool { return fired(t) || ctx.Err() != nil }, func() {
+				select {
+				case <-t.C():
+				case <-ctx.Done():
+					expired = true
+				}
+			})
+			if expired {
+				t.Stop()
+				return
+			}
+			g.waiting.Store(-1)
+			tokens, last = 1, now(ctx)
+		}
+		tokens--
+		waited += wait
+		if overdue(ctx) {
+			return
+		}
+		runProcess(withWait(child, wait), w, c)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if overdue(ctx) && !allExecuted(g.children) {
+		return
+	}
+	g.isExecuted.set(true)
+	rec := Record{Timeout: since(ctx, sp.startedAt), Wait: waited, Note: fmt.Sprintf("%d per %s", g.limit, g.per)}
+	sp.end(ctx, w, g.String(), rec)
+}
+
+// IsExecuted returns true if all children have been run
+func (g *ThrottledGroup) IsExecuted() bool {
+	return g.isExecuted.get()
+}
+
+// ResetRun clears the state of the last run
+func (g *ThrottledGroup) ResetRun() {
+	g.isExecuted.set(false)
+	g.waiting.Store(-1)
+}
+
+// String returns the rate of the group followed by its children
+func (g *ThrottledGroup) String() string {
+	names := make([]string, len(g.children))
+	for i, c := range g.children {
+		names[i] = c.String()
+	}
+	return "throttled(" + strings.Join(names, ", ") + ")"
+}
+
+// Describe returns the rate of the group
+func (g *ThrottledGroup) Describe() string {
+	return fmt.Sprintf("throttled, %d per %s", g.limit, g.per)
+}
+
+// Children returns the children of the group
+func (g *ThrottledGroup) Children() []Proccess {
+	return g.children
+}
+
+func (g *ThrottledGroup) sequential() {}
+
+// unexecutedNote annotates the children left behind by a token wait the deadline interrupted
+func (g *ThrottledGroup) unexecutedNote(i int) string {
+	if waiting := g.waiting.Load(); waiting >= 0 && int64(i) >= waiting {
+		return "throttled"
+	}
+	return ""
+}
+
+type waitKey struct{}
+
+// withWait returns a child of ctx whose first record is charged with the time waited
+// before the process started
+func withWait(ctx context.Context, wait time.Duration) context.Context {
+	if wait <= 0 {
+		return ctx
+	}
+	slot := new(atomic.Int64)
+	slot.Store(int64(wait))
+	return context.WithValue(ctx, waitKey{}, slot)
+}
+
+// takeWait returns the time waited charged to ctx, once
+func takeWait(ctx context.Context) time.Duration {
+	if slot, ok := ctx.Value(waitKey{}).(*atomic.Int64); ok {
+		return time.Duration(slot.Swap(0))
+	}
+	return 0
+}
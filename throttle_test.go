@@ -0,0 +1,59 @@
+package t0simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThrottledWaitNotedInDefaultReport(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulator("throttled", 200, WithVirtualClock(), WithOutput(&out))
+	s.RegisterFunctions(Throttled(1, 100*time.Millisecond, NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(10)))
+	s.Run()
+	for _, row := range []string{
+		"  b             |10              |90            |ok     |waited 90 ms              |\n",
+		"throttled(a, b) |110             |90            |ok     |1 per 100ms, waited 90 ms |\n",
+	} {
+		if !strings.Contains(out.String(), row) {
+			t.Errorf("report misses %q\n%s", row, out.String())
+		}
+	}
+}
+
+func TestThrottledLastChildPastDeadline(t *testing.T) {
+	s := NewSimulator("throttled", 100, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(Throttled(2, time.Second, NewFunction("a").WithTimeout(40), NewFunction("b").WithTimeout(80)))
+	res, _ := s.Run()
+	groups := res.ByName("throttled(a, b)")
+	if len(groups) != 1 || groups[0].State() != StateInterrupted {
+		t.Fatalf("records of the group %+v, want a single interrupted one", groups)
+	}
+	if b := res.ByName("b"); len(b) != 1 || b[0].State() != StateInterrupted {
+		t.Errorf("records of b %+v, want a single interrupted one", b)
+	}
+	if sum := res.Summary; sum.Executed != 0 || sum.Registered != 1 {
+		t.Errorf("executed %d of %d", sum.Executed, sum.Registered)
+	}
+}
+
+func TestThrottledWaitNotCarriedOver(t *testing.T) {
+	g := Throttled(1, 100*time.Millisecond, NewFunction("a").WithTimeout(10), NewFunction("b").WithTimeout(10))
+	// the deadline interrupts the first run while b waits for a token
+	first := NewSimulator("first", 50, WithVirtualClock(), WithVerbosity(Quiet))
+	first.RegisterFunctions(g)
+	res, _ := first.Run()
+	if b := res.ByName("b"); len(b) != 1 || b[0].Note != "throttled" {
+		t.Fatalf("records of b %+v, want a single throttled one", b)
+	}
+	// and the second one before the group starts
+	second := NewSimulator("second", 50, WithVirtualClock(), WithVerbosity(Quiet))
+	second.RegisterFunctions(NewFunction("x").WithTimeout(80), g)
+	res, _ = second.Run()
+	for _, name := range []string{"a", "b"} {
+		if recs := res.ByName(name); len(recs) != 1 || recs[0].State() != StateNotStarted || recs[0].Note != "" {
+			t.Errorf("records of %s %+v, want a single never started one without a note", name, recs)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package t0simulator
+
+import (
+	"strconv"
+	"time"
+)
+
+// Unit denotes the unit durations are displayed in
+type Unit int
+
+const (
+	// UnitMillisecond displays durations in whole milliseconds, it is the default unit
+	UnitMillisecond Unit = iota
+	// UnitMicrosecond displays durations in whole microseconds
+	UnitMicrosecond
+	// UnitSecond displays durations in seconds with millisecond decimals
+	UnitSecond
+	// UnitAuto picks a unit for every run according to its budget
+	UnitAuto
+)
+
+// resolve returns the unit to display a run with the given budget in
+func (u Unit) resolve(budget time.Duration) Unit {
+	if u != UnitAuto {
+		return u
+	}
+	switch {
+	case budget < 10*time.Millisecond:
+		return UnitMicrosecond
+	case budget >= 10*time.Second:
+		return UnitSecond
+	}
+	return UnitMillisecond
+}
+
+func (u Unit) String() string {
+	switch u {
+	case UnitMicrosecond:
+		return "µs"
+	case UnitSecond:
+		return "s"
+	case UnitAuto:
+		return "auto"
+	}
+	return "ms"
+}
+
+// format returns d as a number of u
+func (u Unit) format(d time.Duration) string {
+	switch u {
+	case UnitMicrosecond:
+		return strconv.FormatInt(d.Microseconds(), 10)
+	case UnitSecond:
+		return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10)
+}
+
+// WithUnit sets the unit durations are displayed in by the table report
+func WithUnit(u Unit) Option {
+	return func(s *Simulator) error {
+		s.unit = u
+		return nil
+	}
+}
@@ -0,0 +1,34 @@
+package t0simulator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubMillisecondBudget(t *testing.T) {
+	var out bytes.Buffer
+	s := NewSimulatorD("fast", 2*time.Millisecond, WithVirtualClock(), WithOutput(&out), WithUnit(UnitAuto))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeoutD(300*time.Microsecond),
+		NewFunction("b").WithTimeoutD(300*time.Microsecond),
+		NewFunction("c").WithTimeoutD(300*time.Microsecond),
+		NewFunction("d").WithDynamicContext(0.5, false),
+	)
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := []time.Duration{1700 * time.Microsecond, 1400 * time.Microsecond, 1100 * time.Microsecond, 550 * time.Microsecond}
+	for i, rec := range res.Records {
+		if rec.Remaining != remaining[i] || rec.Consumed <= 0 {
+			t.Errorf("%s: consumed %v, remaining %v, want %v", rec.Name, rec.Consumed, rec.Remaining, remaining[i])
+		}
+	}
+	for _, row := range []string{"a    |300             |1700", "d    |550             |550"} {
+		if !strings.Contains(out.String(), row) {
+			t.Errorf("report misses %q\n%s", row, out.String())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package t0simulator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Validator is implemented by processes checking their configuration, Validate returns
+// an error telling what is invalid
+type Validator interface {
+	Validate() error
+}
+
+// Validate checks the configuration of the simulator and of its processes: the budget
+// must be positive, at least one process must be registered, and every process must be
+// valid. The error matches ErrInvalidConfig and names the offending processes. Run
+// calls it first.
+func (s *Simulator) Validate() error {
+	s.running.Lock()
+	defer s.running.Unlock()
+	return s.validate()
+}
+
+// validate is Validate for the configuration c
+func (c *config) validate() error {
+	var errs []error
+	if c.deadline.IsZero() && c.timeout <= 0 {
+		errs = append(errs, fmt.Errorf("simulator %q: budget %s not positive", c.name, c.timeout))
+	}
+	if c.deadline.IsZero() && c.reserved >= c.timeout && c.timeout > 0 {
+		errs = append(errs, fmt.Errorf("simulator %q: reserved %s not lower than the budget %s", c.name, c.reserved, c.timeout))
+	}
+	if len(c.process) == 0 {
+		errs = append(errs, fmt.Errorf("simulator %q: no registered process", c.name))
+	}
+	errs = append(errs, validateAll(c.process)...)
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return nil
+}
+
+// validateAll returns the errors of the invalid processes of ps and of their children
+func validateAll(ps []Proccess) []error {
+	var errs []error
+	for _, p := range ps {
+		if isNil(p) {
+			errs = append(errs, ErrNilProcess)
+			continue
+		}
+		if v, ok := p.(Validator); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("process %q: %w", p.String(), err))
+			}
+		}
+		if c, ok := p.(Composite); ok {
+			errs = append(errs, validateAll(c.Children())...)
+		}
+	}
+	return errs
+}
+
+// Validate returns an error when the weight is not in (0,1]
+func (f *FunctionWithDynamiContext) Validate() error {
+	if !validWeight(f.weight) {
+		return fmt.Errorf("weight %v out of range (0,1]", f.weight)
+	}
+	return nil
+}
+
+// validWeight returns true if weight is in (0,1], NaN is not
+func validWeight(weight float64) bool {
+	return weight > 0 && weight <= 1
+}
+
+// Validate returns an error when the timeout is negative
+func (f *FunctionWithTimeout) Validate() error {
+	if f.timeout < 0 {
+		return fmt.Errorf("negative timeout %s", f.timeout)
+	}
+	return nil
+}
+
+// Validate returns an error when the budget of the simulator is not positive, its
+// processes are validated as children
+func (p *simulatorProcess) Validate() error {
+	if p.s.deadline.IsZero() && p.s.timeout <= 0 {
+		return fmt.Errorf("budget %s not positive", p.s.timeout)
+	}
+	return nil
+}
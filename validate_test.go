@@ -0,0 +1,45 @@
+package t0simulator
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDynamicContextWeight(t *testing.T) {
+	for _, weight := range []float64{0.01, 0.5, 1} {
+		if _, err := NewFunction("f").WithDynamicContextE(weight, false); err != nil {
+			t.Errorf("weight %v: %v", weight, err)
+		}
+	}
+	for _, weight := range []float64{0, -0.5, 1.01, math.NaN(), math.Inf(1)} {
+		if _, err := NewFunction("f").WithDynamicContextE(weight, false); err == nil {
+			t.Errorf("weight %v: no error", weight)
+		}
+	}
+}
+
+func TestDynamicContextWholeWeightClampedByParent(t *testing.T) {
+	s := NewSimulator("whole", 200, WithVirtualClock(), WithVerbosity(Quiet))
+	s.RegisterFunctions(
+		NewFunction("a").WithTimeout(120),
+		NewFallback(NewFunction("primary").WithDynamicContext(1, false), NewFunction("secondary").WithTimeout(10), 30),
+		NewFunction("whole").WithDynamicContext(1, false),
+	)
+	res, err := s.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]time.Duration{"primary": 30 * time.Millisecond, "whole": 50 * time.Millisecond} {
+		recs := res.ByName(name)
+		if len(recs) != 1 {
+			t.Fatalf("%s: %d records", name, len(recs))
+		}
+		if rec := recs[0]; rec.Status() != StatusOK || rec.Timeout != want {
+			t.Errorf("%s: %s after %v, want ok after %v", name, rec.Status(), rec.Timeout, want)
+		}
+	}
+	if res.Remaining != 0 {
+		t.Errorf("remaining %v, want 0", res.Remaining)
+	}
+}
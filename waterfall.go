@@ -0,0 +1,45 @@
+package t0simulator
+
+import "time"
+
+// WaterfallStep denotes a step of the budget waterfall of a run. After is negative
+// when the step overran the budget.
+type WaterfallStep struct {
+	Label    string
+	Before   time.Duration
+	Consumed time.Duration
+	After    time.Duration
+}
+
+// Waterfall returns how the budget stepped down across the run: the Init step, one
+// step per executed registered process in order and a terminal step for the outcome, which is
+// "Time out" when the deadline was reached and "Done" otherwise
+func (r *Result) Waterfall() []WaterfallStep {
+	steps := []WaterfallStep{{Label: "Init", Before: r.Budget, After: r.Budget}}
+	last := r.Budget
+	for _, rec := range r.Records {
+		if !rec.Executed || rec.Depth > 0 {
+			continue
+		}
+		steps = append(steps, WaterfallStep{
+			Label:    rec.Name,
+			Before:   rec.Available,
+			Consumed: rec.Consumed,
+			After:    rec.Remaining,
+		})
+		last = rec.Remaining
+	}
+	label := "Done"
+	switch {
+	case r.TimedOut:
+		label = "Time out"
+	case r.Cancelled:
+		label = "Cancelled"
+	}
+	return append(steps, WaterfallStep{
+		Label:    label,
+		Before:   last,
+		Consumed: last - r.Remaining,
+		After:    r.Remaining,
+	})
+}